@@ -0,0 +1,514 @@
+// Package client is a Go client for the SlateDB demo server. It speaks the
+// slatedb.v2 API, bounds every request with a timeout, retries the requests
+// that only read, and resumes scan streams that break.
+//
+//	c, err := client.New(
+//		client.WithAddress("db.example.com:5423"),
+//		client.WithTLSFiles("ca.pem", "client.pem", "client-key.pem"),
+//	)
+//	if err != nil {
+//		return err
+//	}
+//	defer c.Close()
+//
+//	err = c.Put(ctx, []byte("user:1"), []byte("Alice"), 0)
+//
+// Connections use TLS unless WithInsecure is given. Methods return the
+// errors of the server as *Error, which match ErrNotFound and the other
+// sentinel errors with errors.Is.
+package client
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"time"
+
+	"github.com/TFMV/slatedb_demo/internal/tlsconfig"
+	pb "github.com/TFMV/slatedb_demo/proto/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// Client is a connection to a SlateDB server. It is safe for concurrent use.
+type Client struct {
+	rpc      pb.SlateDBClient
+	conn     *grpc.ClientConn
+	reloader *tlsconfig.Reloader // nil unless WithTLSFiles is used
+	call     callSettings
+}
+
+// New returns a client for the server at the address set with WithAddress.
+// The connection is made lazily, by the first request.
+func New(opts ...Option) (*Client, error) {
+	cfg := defaultConfig()
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	creds, reloader, err := cfg.transportCredentials()
+	if err != nil {
+		return nil, err
+	}
+	dialOptions := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithChainUnaryInterceptor(append([]grpc.UnaryClientInterceptor{unaryInterceptor}, cfg.unary...)...),
+		grpc.WithChainStreamInterceptor(append([]grpc.StreamClientInterceptor{streamInterceptor}, cfg.stream...)...),
+	}
+	if cfg.token != "" {
+		dialOptions = append(dialOptions,
+			grpc.WithPerRPCCredentials(tokenCredentials{token: cfg.token, requireTLS: !cfg.insecure}))
+	}
+	conn, err := grpc.NewClient(cfg.address, append(dialOptions, cfg.dialOptions...)...)
+	if err != nil {
+		if reloader != nil {
+			reloader.Close()
+		}
+		return nil, fmt.Errorf("failed to connect to server: %v", err)
+	}
+
+	return &Client{
+		rpc:      pb.NewSlateDBClient(conn),
+		conn:     conn,
+		reloader: reloader,
+		call:     cfg.call.clone(),
+	}, nil
+}
+
+// With returns a client that shares the connection of c, with the timeouts
+// and retry policy changed by opts. Options that configure the connection
+// have no effect. Closing either client closes the connection.
+func (c *Client) With(opts ...Option) *Client {
+	cfg := config{call: c.call.clone()}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &Client{rpc: c.rpc, conn: c.conn, reloader: c.reloader, call: cfg.call}
+}
+
+// Close closes the connection to the server.
+func (c *Client) Close() error {
+	if c.reloader != nil {
+		c.reloader.Close()
+	}
+	return c.conn.Close()
+}
+
+// callOpt passes the settings of c to the interceptor.
+func (c *Client) callOpt() grpc.CallOption {
+	return callOption{settings: &c.call}
+}
+
+// BatchResult counts the keys a batch request applied and rejected.
+type BatchResult struct {
+	Succeeded int
+	Failed    int
+}
+
+// Basic operations
+
+// Put stores value under key. A non-zero ttl makes the key expire after that
+// long, rounded up to whole seconds.
+func (c *Client) Put(ctx context.Context, key, value []byte, ttl time.Duration) error {
+	_, err := c.rpc.Put(ctx, &pb.PutRequest{
+		Key:        key,
+		Value:      value,
+		TtlSeconds: ttlSeconds(ttl),
+		Namespace:  c.call.namespace,
+	}, c.callOpt())
+	return err
+}
+
+// ttlSeconds converts ttl to the whole seconds of the API, rounding away
+// from zero so that a ttl under a second is not sent as no ttl at all.
+func ttlSeconds(ttl time.Duration) int64 {
+	seconds := int64(ttl / time.Second)
+	switch {
+	case ttl%time.Second > 0:
+		seconds++
+	case ttl%time.Second < 0:
+		seconds--
+	}
+	return seconds
+}
+
+// Get returns the value of key, which may be empty. It fails with
+// ErrNotFound if the key does not exist.
+func (c *Client) Get(ctx context.Context, key []byte) ([]byte, error) {
+	entry, err := c.GetEntry(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return entry.Value, nil
+}
+
+// GetEntry is Get returning the entry of key, with its expiry.
+func (c *Client) GetEntry(ctx context.Context, key []byte) (*pb.KeyValue, error) {
+	resp, err := c.rpc.Get(ctx, &pb.GetRequest{
+		Key:        key,
+		Namespace:  c.call.namespace,
+		SnapshotId: c.call.snapshot,
+	}, c.callOpt())
+	if err != nil {
+		return nil, err
+	}
+	value := resp.Value
+	if value == nil {
+		value = []byte{}
+	}
+	return &pb.KeyValue{Key: key, Value: value, ExpireAt: resp.ExpireAt}, nil
+}
+
+// Delete removes key. Deleting a missing key is not an error.
+func (c *Client) Delete(ctx context.Context, key []byte) error {
+	_, err := c.rpc.Delete(ctx, &pb.DeleteRequest{Key: key, Namespace: c.call.namespace}, c.callOpt())
+	return err
+}
+
+// Ttl reports whether key exists and how long it has left before it
+// expires.
+func (c *Client) Ttl(ctx context.Context, key []byte) (*pb.TtlResponse, error) {
+	return c.rpc.Ttl(ctx, &pb.TtlRequest{Key: key, Namespace: c.call.namespace}, c.callOpt())
+}
+
+// Batch operations
+
+// BatchPut stores entries, each with its own expiry. Entries are applied
+// one by one; the result counts the ones the server rejected.
+func (c *Client) BatchPut(ctx context.Context, entries []*pb.KeyValue) (BatchResult, error) {
+	resp, err := c.rpc.BatchPut(ctx, &pb.BatchPutRequest{Entries: entries, Namespace: c.call.namespace}, c.callOpt())
+	if err != nil {
+		return BatchResult{}, err
+	}
+	return BatchResult{Succeeded: int(resp.SuccessCount), Failed: int(resp.FailureCount)}, nil
+}
+
+// BatchGet returns the entries of the keys that exist, in request order, and
+// the keys that do not.
+func (c *Client) BatchGet(ctx context.Context, keys [][]byte) ([]*pb.KeyValue, [][]byte, error) {
+	resp, err := c.rpc.BatchGet(ctx, &pb.BatchGetRequest{
+		Keys:       keys,
+		Namespace:  c.call.namespace,
+		SnapshotId: c.call.snapshot,
+	}, c.callOpt())
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp.Entries, resp.MissingKeys, nil
+}
+
+// BatchDelete removes keys.
+func (c *Client) BatchDelete(ctx context.Context, keys [][]byte) (BatchResult, error) {
+	resp, err := c.rpc.BatchDelete(ctx, &pb.BatchDeleteRequest{Keys: keys, Namespace: c.call.namespace}, c.callOpt())
+	if err != nil {
+		return BatchResult{}, err
+	}
+	return BatchResult{Succeeded: int(resp.SuccessCount), Failed: int(resp.FailureCount)}, nil
+}
+
+// Range deletes
+
+// DeleteRange removes every key in [start, end), where an empty end means
+// no upper bound, and returns how many keys it removed. With dryRun set it
+// only counts them.
+func (c *Client) DeleteRange(ctx context.Context, start, end []byte, dryRun bool) (int64, error) {
+	resp, err := c.rpc.DeleteRange(ctx, &pb.DeleteRangeRequest{
+		StartKey:  start,
+		EndKey:    end,
+		DryRun:    dryRun,
+		Namespace: c.call.namespace,
+	}, c.callOpt())
+	if err != nil {
+		return 0, err
+	}
+	return resp.DeletedKeys, nil
+}
+
+// DeletePrefix removes every key with the given non-empty prefix and returns
+// how many keys it removed. With dryRun set it only counts them.
+func (c *Client) DeletePrefix(ctx context.Context, prefix []byte, dryRun bool) (int64, error) {
+	resp, err := c.rpc.DeletePrefix(ctx, &pb.DeletePrefixRequest{Prefix: prefix, DryRun: dryRun, Namespace: c.call.namespace}, c.callOpt())
+	if err != nil {
+		return 0, err
+	}
+	return resp.DeletedKeys, nil
+}
+
+// Conditional operations
+
+// CompareAndSwap sets key to newValue if it currently holds expected, or if
+// it does not exist when expectAbsent is set. On a mismatch the error carries
+// the current value; see ConditionFailure.
+func (c *Client) CompareAndSwap(ctx context.Context, key, expected []byte, expectAbsent bool, newValue []byte) error {
+	_, err := c.rpc.CompareAndSwap(ctx, &pb.CompareAndSwapRequest{
+		Key:           key,
+		ExpectedValue: expected,
+		ExpectAbsent:  expectAbsent,
+		NewValue:      newValue,
+		Namespace:     c.call.namespace,
+	}, c.callOpt())
+	return err
+}
+
+// PutIfAbsent stores value under key unless the key exists.
+func (c *Client) PutIfAbsent(ctx context.Context, key, value []byte) error {
+	_, err := c.rpc.PutIfAbsent(ctx, &pb.PutIfAbsentRequest{Key: key, Value: value, Namespace: c.call.namespace}, c.callOpt())
+	return err
+}
+
+// DeleteIfEquals removes key if it holds expected.
+func (c *Client) DeleteIfEquals(ctx context.Context, key, expected []byte) error {
+	_, err := c.rpc.DeleteIfEquals(ctx, &pb.DeleteIfEqualsRequest{
+		Key:           key,
+		ExpectedValue: expected,
+		Namespace:     c.call.namespace,
+	}, c.callOpt())
+	return err
+}
+
+// ConditionFailure returns the current value attached to the error of a
+// failed conditional operation, or nil if err is not such an error.
+func ConditionFailure(err error) *pb.CurrentValue {
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != codes.FailedPrecondition {
+		return nil
+	}
+	for _, detail := range st.Details() {
+		if current, ok := detail.(*pb.CurrentValue); ok {
+			return current
+		}
+	}
+	return nil
+}
+
+// Transactional operations
+
+// Write applies ops atomically: either all of them are applied or none are,
+// even if the server crashes while applying them.
+func (c *Client) Write(ctx context.Context, ops []*pb.WriteOperation) error {
+	_, err := c.rpc.Write(ctx, &pb.WriteRequest{Operations: ops, Namespace: c.call.namespace}, c.callOpt())
+	return err
+}
+
+// Scanning operations
+
+// PrefixScan returns the first limit entries with the given prefix.
+func (c *Client) PrefixScan(ctx context.Context, prefix []byte, limit int32) ([]*pb.KeyValue, error) {
+	entries, _, err := c.PrefixScanPage(ctx, prefix, limit, "")
+	return entries, err
+}
+
+// RangeScan returns the first limit entries in [startKey, endKey). An empty
+// endKey leaves the range unbounded.
+func (c *Client) RangeScan(ctx context.Context, startKey, endKey []byte, limit int32) ([]*pb.KeyValue, error) {
+	entries, _, err := c.RangeScanPage(ctx, startKey, endKey, limit, "")
+	return entries, err
+}
+
+// PrefixScanPage returns the page of entries with the given prefix that
+// starts at pageToken, along with the token of the next page. An empty
+// token requests the first page; an empty next token means there are no
+// more pages.
+func (c *Client) PrefixScanPage(ctx context.Context, prefix []byte, limit int32, pageToken string) ([]*pb.KeyValue, string, error) {
+	resp, err := c.rpc.PrefixScan(ctx, &pb.PrefixScanRequest{
+		Prefix:     prefix,
+		Limit:      limit,
+		PageToken:  pageToken,
+		Namespace:  c.call.namespace,
+		SnapshotId: c.call.snapshot,
+	}, c.callOpt())
+	if err != nil {
+		return nil, "", err
+	}
+	return resp.Entries, resp.NextPageToken, nil
+}
+
+// RangeScanPage is the RangeScan counterpart of PrefixScanPage.
+func (c *Client) RangeScanPage(ctx context.Context, startKey, endKey []byte, limit int32, pageToken string) ([]*pb.KeyValue, string, error) {
+	resp, err := c.rpc.RangeScan(ctx, &pb.RangeScanRequest{
+		StartKey:   startKey,
+		EndKey:     endKey,
+		Limit:      limit,
+		PageToken:  pageToken,
+		Namespace:  c.call.namespace,
+		SnapshotId: c.call.snapshot,
+	}, c.callOpt())
+	if err != nil {
+		return nil, "", err
+	}
+	return resp.Entries, resp.NextPageToken, nil
+}
+
+// WalkPrefix calls fn with every page of entries with the given prefix,
+// in key order, until the keyspace is exhausted or fn returns an error.
+func (c *Client) WalkPrefix(ctx context.Context, prefix []byte, pageSize int32, fn func([]*pb.KeyValue) error) error {
+	token := ""
+	for {
+		entries, next, err := c.PrefixScanPage(ctx, prefix, pageSize, token)
+		if err != nil {
+			return err
+		}
+		if err := fn(entries); err != nil {
+			return err
+		}
+		if next == "" {
+			return nil
+		}
+		token = next
+	}
+}
+
+// PrefixScanStream returns an iterator over the entries with the given
+// prefix. Entries are received in chunks as the loop consumes them, and
+// breaking out of the loop cancels the stream. A stream that breaks with a
+// retryable error is reopened after the last entry received.
+func (c *Client) PrefixScanStream(ctx context.Context, prefix []byte, limit int32) iter.Seq2[*pb.KeyValue, error] {
+	return c.scanStream(ctx, limit, func(ctx context.Context, after []byte, limit int32) (chunkStream, error) {
+		if after == nil {
+			return c.rpc.PrefixScanStream(ctx, &pb.PrefixScanRequest{
+				Prefix:     prefix,
+				Limit:      limit,
+				Namespace:  c.call.namespace,
+				SnapshotId: c.call.snapshot,
+			})
+		}
+		return c.rpc.RangeScanStream(ctx, &pb.RangeScanRequest{
+			StartKey:   keyAfter(after),
+			EndKey:     prefixEnd(prefix),
+			Limit:      limit,
+			Namespace:  c.call.namespace,
+			SnapshotId: c.call.snapshot,
+		})
+	})
+}
+
+// RangeScanStream returns an iterator over the entries in [startKey, endKey).
+func (c *Client) RangeScanStream(ctx context.Context, startKey, endKey []byte, limit int32) iter.Seq2[*pb.KeyValue, error] {
+	return c.scanStream(ctx, limit, func(ctx context.Context, after []byte, limit int32) (chunkStream, error) {
+		start := startKey
+		if after != nil {
+			start = keyAfter(after)
+		}
+		return c.rpc.RangeScanStream(ctx, &pb.RangeScanRequest{
+			StartKey:   start,
+			EndKey:     endKey,
+			Limit:      limit,
+			Namespace:  c.call.namespace,
+			SnapshotId: c.call.snapshot,
+		})
+	})
+}
+
+// Change notifications
+
+// Watch streams the events for keys with prefix, starting at fromSequence,
+// or with new events if it is 0. The stream ends when ctx is canceled or an
+// error is yielded.
+func (c *Client) Watch(ctx context.Context, prefix []byte, fromSequence uint64) iter.Seq2[*pb.WatchEvent, error] {
+	return func(yield func(*pb.WatchEvent, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		stream, err := c.rpc.Watch(ctx, &pb.WatchRequest{
+			Prefix:       prefix,
+			FromSequence: fromSequence,
+			Namespace:    c.call.namespace,
+		})
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		for {
+			event, err := stream.Recv()
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if !yield(event, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Statistics and monitoring
+
+// GetStats returns the statistics of the namespace, with a breakdown for
+// each of the given prefixes. In the default namespace they cover the whole
+// database, with a breakdown for each namespace.
+func (c *Client) GetStats(ctx context.Context, prefixes ...[]byte) (*pb.GetStatsResponse, error) {
+	return c.rpc.GetStats(ctx, &pb.GetStatsRequest{Prefixes: prefixes, Namespace: c.call.namespace}, c.callOpt())
+}
+
+// Health checks that the server serves the slatedb.v2 API, with the
+// standard gRPC health check, which needs no token. A server that does not,
+// such as one that is shutting down or was fenced, fails with
+// ErrUnavailable.
+func (c *Client) Health(ctx context.Context) error {
+	resp, err := healthpb.NewHealthClient(c.conn).Check(ctx,
+		&healthpb.HealthCheckRequest{Service: pb.SlateDB_ServiceDesc.ServiceName}, c.callOpt())
+	if err != nil {
+		return err
+	}
+	if resp.Status != healthpb.HealthCheckResponse_SERVING {
+		return wrapError(status.Errorf(codes.Unavailable, "server is %s", resp.Status))
+	}
+	return nil
+}
+
+// Namespaces
+
+// CreateNamespace creates the namespace called name.
+func (c *Client) CreateNamespace(ctx context.Context, name string) error {
+	_, err := c.rpc.CreateNamespace(ctx, &pb.CreateNamespaceRequest{Name: name}, c.callOpt())
+	return err
+}
+
+// ListNamespaces returns the namespaces that were created, by name, with
+// their key counts and sizes.
+func (c *Client) ListNamespaces(ctx context.Context) ([]*pb.NamespaceStats, error) {
+	resp, err := c.rpc.ListNamespaces(ctx, &pb.ListNamespacesRequest{}, c.callOpt())
+	if err != nil {
+		return nil, err
+	}
+	return resp.Namespaces, nil
+}
+
+// DropNamespace deletes the namespace called name and all of its keys, and
+// returns how many keys were deleted.
+func (c *Client) DropNamespace(ctx context.Context, name string) (int64, error) {
+	resp, err := c.rpc.DropNamespace(ctx, &pb.DropNamespaceRequest{Name: name}, c.callOpt())
+	if err != nil {
+		return 0, err
+	}
+	return resp.DeletedKeys, nil
+}
+
+// Snapshots
+
+// Snapshot is a consistent view of the database; see WithSnapshot.
+type Snapshot struct {
+	ID string
+	// ExpireAt is when the lease of the snapshot runs out, unless a read
+	// from the snapshot renews it.
+	ExpireAt time.Time
+}
+
+// CreateSnapshot creates a snapshot of the database leased for ttl, rounded
+// up to whole seconds, or for the default lease of the server if ttl is 0.
+// Every read from the snapshot renews the lease.
+func (c *Client) CreateSnapshot(ctx context.Context, ttl time.Duration) (Snapshot, error) {
+	resp, err := c.rpc.CreateSnapshot(ctx, &pb.CreateSnapshotRequest{TtlSeconds: ttlSeconds(ttl)}, c.callOpt())
+	if err != nil {
+		return Snapshot{}, err
+	}
+	return Snapshot{ID: resp.SnapshotId, ExpireAt: time.Unix(resp.ExpireAt, 0)}, nil
+}
+
+// ReleaseSnapshot discards the snapshot id.
+func (c *Client) ReleaseSnapshot(ctx context.Context, id string) error {
+	_, err := c.rpc.ReleaseSnapshot(ctx, &pb.ReleaseSnapshotRequest{SnapshotId: id}, c.callOpt())
+	return err
+}
@@ -0,0 +1,103 @@
+package client
+
+import (
+	"errors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Errors returned for failed calls match one of these with errors.Is,
+// according to their status code:
+//
+//	value, err := c.Get(ctx, key)
+//	if errors.Is(err, client.ErrNotFound) {
+//		// the key does not exist
+//	}
+var (
+	ErrInvalidArgument    = errors.New("invalid argument")
+	ErrNotFound           = errors.New("not found")
+	ErrAlreadyExists      = errors.New("already exists")
+	ErrFailedPrecondition = errors.New("failed precondition")
+	ErrUnauthenticated    = errors.New("unauthenticated")
+	ErrPermissionDenied   = errors.New("permission denied")
+	ErrUnavailable        = errors.New("unavailable")
+)
+
+var codeErrors = map[codes.Code]error{
+	codes.InvalidArgument:    ErrInvalidArgument,
+	codes.NotFound:           ErrNotFound,
+	codes.AlreadyExists:      ErrAlreadyExists,
+	codes.FailedPrecondition: ErrFailedPrecondition,
+	codes.Unauthenticated:    ErrUnauthenticated,
+	codes.PermissionDenied:   ErrPermissionDenied,
+	codes.Unavailable:        ErrUnavailable,
+}
+
+// Reasons given by the server for some errors, in Error.Reason.
+const (
+	ReasonKeyNotFound       = "KEY_NOT_FOUND"
+	ReasonNamespaceNotFound = "NAMESPACE_NOT_FOUND"
+	ReasonNamespaceExists   = "NAMESPACE_EXISTS"
+	ReasonSnapshotNotFound  = "SNAPSHOT_NOT_FOUND"
+	ReasonConditionFailed   = "CONDITION_FAILED"
+	ReasonReadOnly          = "READ_ONLY"
+	ReasonFenced            = "FENCED"
+	ReasonLeaseExpired      = "LEASE_EXPIRED"
+)
+
+// Error is a failed call, with the details given by the server. It is still
+// a gRPC status error: status.Code and status.FromError accept it.
+type Error struct {
+	Code    codes.Code
+	Message string
+	// Reason names the failure, such as ReasonKeyNotFound, and Metadata
+	// describes it, if the server gave an ErrorInfo.
+	Reason   string
+	Metadata map[string]string
+	// Field is the invalid request field of an INVALID_ARGUMENT error, if
+	// the server named one.
+	Field string
+
+	status *status.Status
+}
+
+func (e *Error) Error() string {
+	return e.status.Err().Error()
+}
+
+// Is reports whether target is the sentinel error of the code of e.
+func (e *Error) Is(target error) bool {
+	return codeErrors[e.Code] == target
+}
+
+// GRPCStatus returns the status of the call.
+func (e *Error) GRPCStatus() *status.Status {
+	return e.status
+}
+
+// wrapError returns err as an *Error if it is a gRPC status error, and
+// unchanged otherwise.
+func wrapError(err error) error {
+	st, ok := status.FromError(err)
+	if !ok || st.Code() == codes.OK {
+		return err
+	}
+	if _, wrapped := err.(*Error); wrapped {
+		return err
+	}
+
+	e := &Error{Code: st.Code(), Message: st.Message(), status: st}
+	for _, detail := range st.Details() {
+		switch detail := detail.(type) {
+		case *errdetails.ErrorInfo:
+			e.Reason, e.Metadata = detail.Reason, detail.Metadata
+		case *errdetails.BadRequest:
+			if len(detail.FieldViolations) > 0 {
+				e.Field = detail.FieldViolations[0].Field
+			}
+		}
+	}
+	return e
+}
@@ -0,0 +1,213 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"maps"
+	"time"
+
+	"github.com/TFMV/slatedb_demo/internal/tlsconfig"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+const (
+	// DefaultAddress is the address the server listens on by default.
+	DefaultAddress = "localhost:5423"
+
+	// DefaultTimeout bounds each attempt of a unary request.
+	DefaultTimeout = 5 * time.Second
+
+	// certReloadInterval is how often the files given to WithTLSFiles are
+	// checked for changes.
+	certReloadInterval = 10 * time.Second
+)
+
+// Option configures a Client.
+type Option func(*config)
+
+// config holds the settings of a Client. The connection settings are used
+// by New only; the call settings, including the namespace, can also be
+// changed with Client.With.
+type config struct {
+	address     string
+	tls         *tls.Config
+	tlsFiles    *tlsconfig.Files
+	insecure    bool
+	token       string
+	unary       []grpc.UnaryClientInterceptor
+	stream      []grpc.StreamClientInterceptor
+	dialOptions []grpc.DialOption
+
+	call callSettings
+}
+
+func defaultConfig() config {
+	return config{
+		address: DefaultAddress,
+		call: callSettings{
+			timeout: DefaultTimeout,
+			retry:   DefaultRetryPolicy,
+		},
+	}
+}
+
+// callSettings are the timeouts and retries applied to every call.
+type callSettings struct {
+	// timeout bounds each attempt of a unary request, unless timeouts has
+	// an entry for its RPC.
+	timeout  time.Duration
+	timeouts map[string]time.Duration
+
+	// retry applies to the idempotent calls and to scan streams.
+	retry RetryPolicy
+
+	// namespace is sent with every request that reads or writes keys.
+	namespace string
+
+	// snapshot is sent with Get, BatchGet and the scans.
+	snapshot string
+}
+
+// transportCredentials returns the credentials of the connection, and the
+// reloader of the files given to WithTLSFiles, if any.
+func (c *config) transportCredentials() (credentials.TransportCredentials, *tlsconfig.Reloader, error) {
+	if c.insecure {
+		if c.tls != nil || c.tlsFiles != nil {
+			return nil, nil, errors.New("WithInsecure cannot be combined with TLS options")
+		}
+		return insecure.NewCredentials(), nil, nil
+	}
+	if c.tls != nil && c.tlsFiles != nil {
+		return nil, nil, errors.New("WithTLS cannot be combined with WithTLSFiles")
+	}
+
+	if c.tlsFiles != nil {
+		reloader, err := tlsconfig.NewReloader(*c.tlsFiles, certReloadInterval, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		return credentials.NewTLS(reloader.ClientConfig()), reloader, nil
+	}
+	cfg := c.tls
+	if cfg == nil {
+		cfg = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	return credentials.NewTLS(cfg), nil, nil
+}
+
+func (s callSettings) clone() callSettings {
+	s.timeouts = maps.Clone(s.timeouts)
+	return s
+}
+
+// WithAddress sets the host:port of the server. The default is
+// DefaultAddress.
+func WithAddress(addr string) Option {
+	return func(c *config) { c.address = addr }
+}
+
+// WithTLS sets the TLS configuration of the connection. Without any TLS
+// option the server is verified with the system roots.
+func WithTLS(cfg *tls.Config) Option {
+	return func(c *config) { c.tls = cfg }
+}
+
+// WithTLSFiles verifies the server with the CA certificates in caFile, or
+// the system roots if it is empty, and presents the client certificate in
+// certFile and keyFile to servers that require one. The certificate is
+// reloaded when the files change.
+func WithTLSFiles(caFile, certFile, keyFile string) Option {
+	return func(c *config) {
+		c.tlsFiles = &tlsconfig.Files{CAFile: caFile, CertFile: certFile, KeyFile: keyFile}
+	}
+}
+
+// WithInsecure connects without TLS. It cannot be combined with the other
+// TLS options.
+func WithInsecure() Option {
+	return func(c *config) { c.insecure = true }
+}
+
+// WithToken authenticates every request with a bearer token: an API key or
+// a JWT issued for the server.
+func WithToken(token string) Option {
+	return func(c *config) { c.token = token }
+}
+
+// tokenCredentials sends the token of WithToken with every request. The
+// token is only sent over TLS, unless WithInsecure is given.
+type tokenCredentials struct {
+	token      string
+	requireTLS bool
+}
+
+func (t tokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + t.token}, nil
+}
+
+func (t tokenCredentials) RequireTransportSecurity() bool {
+	return t.requireTLS
+}
+
+// WithTimeout bounds each attempt of a unary request. Zero leaves requests
+// bounded only by the context of the caller. The default is DefaultTimeout.
+func WithTimeout(d time.Duration) Option {
+	return func(c *config) { c.call.timeout = d }
+}
+
+// WithMethodTimeout overrides the timeout of the requests of one RPC, named
+// as in the service definition, for example "PrefixScan".
+func WithMethodTimeout(method string, d time.Duration) Option {
+	return func(c *config) {
+		if c.call.timeouts == nil {
+			c.call.timeouts = make(map[string]time.Duration)
+		}
+		c.call.timeouts[method] = d
+	}
+}
+
+// WithRetryPolicy sets how idempotent calls and scan streams are retried.
+// The default is DefaultRetryPolicy.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(c *config) { c.call.retry = p }
+}
+
+// WithNamespace makes requests read and write the keys of a namespace
+// instead of the default namespace. Combined with Client.With, it gives a
+// client for one tenant:
+//
+//	orders := c.With(client.WithNamespace("orders"))
+func WithNamespace(name string) Option {
+	return func(c *config) { c.call.namespace = name }
+}
+
+// WithSnapshot makes Get, BatchGet and the scans read from a snapshot
+// returned by CreateSnapshot:
+//
+//	snap, err := c.CreateSnapshot(ctx, time.Minute)
+//	...
+//	defer c.ReleaseSnapshot(ctx, snap.ID)
+//	view := c.With(client.WithSnapshot(snap.ID))
+func WithSnapshot(id string) Option {
+	return func(c *config) { c.call.snapshot = id }
+}
+
+// WithUnaryInterceptor adds interceptors to the unary calls. They run for
+// every attempt, inside the retries of the client.
+func WithUnaryInterceptor(interceptors ...grpc.UnaryClientInterceptor) Option {
+	return func(c *config) { c.unary = append(c.unary, interceptors...) }
+}
+
+// WithStreamInterceptor adds interceptors to the streaming calls.
+func WithStreamInterceptor(interceptors ...grpc.StreamClientInterceptor) Option {
+	return func(c *config) { c.stream = append(c.stream, interceptors...) }
+}
+
+// WithDialOptions passes options to the gRPC connection, after the ones set
+// by the client.
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return func(c *config) { c.dialOptions = append(c.dialOptions, opts...) }
+}
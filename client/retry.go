@@ -0,0 +1,243 @@
+package client
+
+import (
+	"context"
+	"io"
+	"iter"
+	"math"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	pb "github.com/TFMV/slatedb_demo/proto/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// RetryPolicy controls how idempotent calls are retried when the server is
+// unavailable or does not answer in time.
+type RetryPolicy struct {
+	MaxAttempts    int // including the first; 1 disables retries
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+}
+
+// DefaultRetryPolicy tries idempotent calls up to four times.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    4,
+	InitialBackoff: 100 * time.Millisecond,
+	MaxBackoff:     2 * time.Second,
+	Multiplier:     2,
+}
+
+// backoff returns the delay before retry n, counting from 1. The delay is
+// drawn uniformly up to the exponential backoff ("full jitter"), so that
+// clients that failed together do not retry together.
+func (p RetryPolicy) backoff(n int) time.Duration {
+	ceiling := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(n-1))
+	ceiling = math.Min(ceiling, float64(p.MaxBackoff))
+	return time.Duration(rand.Int64N(int64(ceiling) + 1))
+}
+
+// idempotentMethods are the unary RPCs that only read, so retrying them
+// cannot apply a write twice.
+var idempotentMethods = map[string]bool{
+	pb.SlateDB_Get_FullMethodName:        true,
+	pb.SlateDB_Ttl_FullMethodName:        true,
+	pb.SlateDB_BatchGet_FullMethodName:   true,
+	pb.SlateDB_PrefixScan_FullMethodName: true,
+	pb.SlateDB_RangeScan_FullMethodName:  true,
+	pb.SlateDB_GetStats_FullMethodName:   true,
+
+	pb.SlateDB_ListNamespaces_FullMethodName: true,
+
+	healthpb.Health_Check_FullMethodName: true,
+}
+
+// retryable reports whether a call that failed with err may succeed if it
+// is tried again.
+func retryable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	default:
+		return false
+	}
+}
+
+// sleep waits for d, or returns early with the error of ctx.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// methodName returns the name of the RPC of a full gRPC method name.
+func methodName(fullMethod string) string {
+	return fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+}
+
+// callOption carries the settings of the Client making a call to the
+// interceptor, so that clients returned by With can share a connection.
+type callOption struct {
+	grpc.EmptyCallOption
+	settings *callSettings
+}
+
+// settingsOf returns the settings passed with opts. Calls made without them,
+// through the connection directly, get a single attempt and no timeout.
+func settingsOf(opts []grpc.CallOption) *callSettings {
+	for _, opt := range opts {
+		if o, ok := opt.(callOption); ok {
+			return o.settings
+		}
+	}
+	return &callSettings{retry: RetryPolicy{MaxAttempts: 1}}
+}
+
+// timeoutOf returns the timeout of each attempt of an RPC.
+func (s *callSettings) timeoutOf(fullMethod string) time.Duration {
+	if timeout, ok := s.timeouts[methodName(fullMethod)]; ok {
+		return timeout
+	}
+	return s.timeout
+}
+
+// attemptContext returns the context of an attempt of an RPC, bounded by
+// its timeout. A zero timeout leaves only the deadline of ctx.
+func (s *callSettings) attemptContext(ctx context.Context, fullMethod string) (context.Context, context.CancelFunc) {
+	if timeout := s.timeoutOf(fullMethod); timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// unaryInterceptor bounds every attempt of a unary call by the timeout of
+// its RPC and retries idempotent calls that failed with a retryable error.
+// The context of the caller bounds all attempts together. Errors are
+// returned as *Error.
+func unaryInterceptor(ctx context.Context, method string, req, reply interface{},
+	cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	s := settingsOf(opts)
+	attempts := 1
+	if idempotentMethods[method] {
+		attempts = max(s.retry.MaxAttempts, 1)
+	}
+
+	for attempt := 1; ; attempt++ {
+		attemptCtx, cancel := s.attemptContext(ctx, method)
+		err := invoker(attemptCtx, method, req, reply, cc, opts...)
+		cancel()
+
+		if err == nil || attempt >= attempts || !retryable(err) || ctx.Err() != nil {
+			return wrapError(err)
+		}
+		if sleep(ctx, s.retry.backoff(attempt)) != nil {
+			return wrapError(err)
+		}
+	}
+}
+
+// streamInterceptor returns the errors of streams as *Error.
+func streamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string,
+	streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	stream, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return errorStream{stream}, nil
+}
+
+type errorStream struct {
+	grpc.ClientStream
+}
+
+func (s errorStream) RecvMsg(m interface{}) error {
+	return wrapError(s.ClientStream.RecvMsg(m))
+}
+
+// chunkStream is a stream of scan results.
+type chunkStream interface {
+	Recv() (*pb.ScanChunk, error)
+}
+
+// scanStream returns an iterator over the entries of the streams opened by
+// open. When a stream breaks with a retryable error, open is called again
+// with the last key received, so that the scan resumes after it. Attempts
+// are counted from the last entry received.
+func (c *Client) scanStream(ctx context.Context, limit int32,
+	open func(ctx context.Context, after []byte, limit int32) (chunkStream, error)) iter.Seq2[*pb.KeyValue, error] {
+	return func(yield func(*pb.KeyValue, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		var after []byte
+		var received int32
+		failures := 0
+		for {
+			remaining := limit
+			if limit > 0 {
+				remaining = limit - received
+				if remaining <= 0 {
+					return
+				}
+			}
+
+			stream, err := open(ctx, after, remaining)
+			for err == nil {
+				var chunk *pb.ScanChunk
+				chunk, err = stream.Recv()
+				if err == io.EOF {
+					return
+				}
+				if err != nil {
+					break
+				}
+				for _, entry := range chunk.Entries {
+					if !yield(entry, nil) {
+						return
+					}
+					after = entry.Key
+					received++
+					failures = 0
+				}
+			}
+
+			failures++
+			if failures >= c.call.retry.MaxAttempts || !retryable(err) || ctx.Err() != nil {
+				yield(nil, err)
+				return
+			}
+			if sleep(ctx, c.call.retry.backoff(failures)) != nil {
+				yield(nil, err)
+				return
+			}
+		}
+	}
+}
+
+// keyAfter returns the smallest key that sorts after key.
+func keyAfter(key []byte) []byte {
+	return append(append([]byte(nil), key...), 0)
+}
+
+// prefixEnd returns the smallest key that sorts after every key with
+// prefix, or nil if there is none.
+func prefixEnd(prefix []byte) []byte {
+	end := append([]byte(nil), prefix...)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return nil
+}
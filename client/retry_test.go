@@ -0,0 +1,264 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"testing"
+	"time"
+
+	pb "github.com/TFMV/slatedb_demo/proto/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestBackoff(t *testing.T) {
+	p := RetryPolicy{
+		MaxAttempts:    10,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Multiplier:     2,
+	}
+	ceilings := []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		800 * time.Millisecond,
+		time.Second,
+		time.Second,
+		time.Second,
+	}
+	for i, ceiling := range ceilings {
+		n := i + 1
+		var longest time.Duration
+		for j := 0; j < 1000; j++ {
+			d := p.backoff(n)
+			if d < 0 || d > ceiling {
+				t.Fatalf("backoff(%d) = %v, want between 0 and %v", n, d, ceiling)
+			}
+			longest = max(longest, d)
+		}
+		// The delay is jittered over the whole range, not just below it.
+		if longest <= ceiling/2 {
+			t.Errorf("backoff(%d) was at most %v in 1000 draws, want some above %v", n, longest, ceiling/2)
+		}
+	}
+
+	if d := (RetryPolicy{Multiplier: 2}).backoff(3); d != 0 {
+		t.Errorf("backoff without an initial backoff = %v, want 0", d)
+	}
+}
+
+// scanOpen records the arguments of a call to open.
+type scanOpen struct {
+	after string
+	limit int32
+}
+
+// fakeScan serves a scan of keys in chunks, failing the streams it opens as
+// told by breaks.
+type fakeScan struct {
+	keys  []string
+	chunk int
+	err   error // the error that failing streams and opens return
+
+	// breaks holds, for each call to open, the number of entries its stream
+	// sends before failing, or -1 for the call to fail. Calls past the end
+	// of breaks do not fail.
+	breaks []int
+	opens  []scanOpen
+}
+
+func (f *fakeScan) open(ctx context.Context, after []byte, limit int32) (chunkStream, error) {
+	call := len(f.opens)
+	f.opens = append(f.opens, scanOpen{string(after), limit})
+
+	failAfter := -1
+	if call < len(f.breaks) {
+		if f.breaks[call] < 0 {
+			return nil, f.err
+		}
+		failAfter = f.breaks[call]
+	}
+
+	stream := &fakeStream{chunk: f.chunk, failAfter: failAfter, err: f.err}
+	for _, key := range f.keys {
+		if key > string(after) && (limit <= 0 || len(stream.entries) < int(limit)) {
+			stream.entries = append(stream.entries, &pb.KeyValue{Key: []byte(key)})
+		}
+	}
+	return stream, nil
+}
+
+type fakeStream struct {
+	entries   []*pb.KeyValue
+	sent      int
+	chunk     int
+	failAfter int // -1 for a stream that does not fail
+	err       error
+}
+
+func (s *fakeStream) Recv() (*pb.ScanChunk, error) {
+	if s.sent == s.failAfter {
+		return nil, s.err
+	}
+	if s.sent == len(s.entries) {
+		return nil, io.EOF
+	}
+	n := min(s.chunk, len(s.entries)-s.sent)
+	if s.failAfter >= 0 {
+		n = min(n, s.failAfter-s.sent)
+	}
+	chunk := &pb.ScanChunk{Entries: s.entries[s.sent : s.sent+n]}
+	s.sent += n
+	return chunk, nil
+}
+
+func TestScanStreamResume(t *testing.T) {
+	keys := make([]string, 20)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("k%02d", i)
+	}
+	unavailable := status.Error(codes.Unavailable, "connection reset")
+
+	tests := []struct {
+		name      string
+		limit     int32
+		breaks    []int
+		err       error
+		wantKeys  int // the scan returns keys[:wantKeys]
+		wantErr   bool
+		wantOpens []scanOpen
+	}{
+		{
+			name:      "no failures",
+			wantKeys:  20,
+			wantOpens: []scanOpen{{"", 0}},
+		},
+		{
+			name:      "break mid-chunk",
+			breaks:    []int{7},
+			wantKeys:  20,
+			wantOpens: []scanOpen{{"", 0}, {"k06", 0}},
+		},
+		{
+			name:      "break at a chunk boundary",
+			breaks:    []int{9},
+			wantKeys:  20,
+			wantOpens: []scanOpen{{"", 0}, {"k08", 0}},
+		},
+		{
+			name:      "break before the first entry",
+			breaks:    []int{0},
+			wantKeys:  20,
+			wantOpens: []scanOpen{{"", 0}, {"", 0}},
+		},
+		{
+			name:      "open fails",
+			breaks:    []int{-1, 5},
+			wantKeys:  20,
+			wantOpens: []scanOpen{{"", 0}, {"", 0}, {"k04", 0}},
+		},
+		{
+			name:      "break after the last entry",
+			breaks:    []int{20},
+			wantKeys:  20,
+			wantOpens: []scanOpen{{"", 0}, {"k19", 0}},
+		},
+		{
+			name:      "repeated breaks with progress",
+			breaks:    []int{2, 2, 2, 2, 2},
+			wantKeys:  20,
+			wantOpens: []scanOpen{{"", 0}, {"k01", 0}, {"k03", 0}, {"k05", 0}, {"k07", 0}, {"k09", 0}},
+		},
+		{
+			name:      "limit",
+			limit:     10,
+			breaks:    []int{4},
+			wantKeys:  10,
+			wantOpens: []scanOpen{{"", 10}, {"k03", 6}},
+		},
+		{
+			name:      "break once the limit is reached",
+			limit:     6,
+			breaks:    []int{6},
+			wantKeys:  6,
+			wantOpens: []scanOpen{{"", 6}},
+		},
+		{
+			name:      "too many failures",
+			breaks:    []int{0, -1, 0},
+			wantErr:   true,
+			wantOpens: []scanOpen{{"", 0}, {"", 0}, {"", 0}},
+		},
+		{
+			name:      "too many failures since the last entry",
+			breaks:    []int{5, 0, 0},
+			wantKeys:  5,
+			wantErr:   true,
+			wantOpens: []scanOpen{{"", 0}, {"k04", 0}, {"k04", 0}},
+		},
+		{
+			name:      "not retryable",
+			breaks:    []int{5},
+			err:       status.Error(codes.PermissionDenied, "denied"),
+			wantKeys:  5,
+			wantErr:   true,
+			wantOpens: []scanOpen{{"", 0}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{call: callSettings{retry: RetryPolicy{
+				MaxAttempts:    3,
+				InitialBackoff: time.Millisecond,
+				MaxBackoff:     time.Millisecond,
+				Multiplier:     2,
+			}}}
+			f := &fakeScan{keys: keys, chunk: 3, breaks: tt.breaks, err: tt.err}
+			if f.err == nil {
+				f.err = unavailable
+			}
+
+			var got []string
+			var err error
+			for entry, e := range c.scanStream(context.Background(), tt.limit, f.open) {
+				if e != nil {
+					err = e
+					break
+				}
+				got = append(got, string(entry.Key))
+			}
+
+			if want := keys[:tt.wantKeys]; !slices.Equal(got, want) {
+				t.Errorf("scan returned %v, want %v", got, want)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("scan error = %v, want error %v", err, tt.wantErr)
+			}
+			if !slices.Equal(f.opens, tt.wantOpens) {
+				t.Errorf("opened %v, want %v", f.opens, tt.wantOpens)
+			}
+		})
+	}
+}
+
+func TestScanStreamStop(t *testing.T) {
+	c := &Client{call: callSettings{retry: DefaultRetryPolicy}}
+	f := &fakeScan{keys: []string{"a", "b", "c", "d"}, chunk: 3}
+
+	var got []string
+	for entry, err := range c.scanStream(context.Background(), 0, f.open) {
+		if err != nil {
+			t.Fatalf("scan failed: %v", err)
+		}
+		got = append(got, string(entry.Key))
+		if len(got) == 2 {
+			break
+		}
+	}
+	if !slices.Equal(got, []string{"a", "b"}) || len(f.opens) != 1 {
+		t.Errorf("scan stopped after %v with %d opens, want [a b] and 1 open", got, len(f.opens))
+	}
+}
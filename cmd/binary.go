@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Binary keys and values are entered and displayed with an encoding prefix,
+// for example "hex:deadbeef" or "base64:3q2+7w==". Printable UTF-8 text is
+// shown as is.
+const (
+	hexPrefix    = "hex:"
+	base64Prefix = "base64:"
+)
+
+// binaryFormat selects how binary data is displayed: "hex" (the default)
+// or "base64". It is set with the BINARY_FORMAT environment variable.
+var binaryFormat = os.Getenv("BINARY_FORMAT")
+
+// formatBytes renders b for display. Data that is not printable UTF-8, or
+// that would be mistaken for an encoded value, is encoded in binaryFormat,
+// in a form readBinaryInput accepts back.
+func formatBytes(b []byte) string {
+	if utf8.Valid(b) && strings.IndexFunc(string(b), isNonPrintable) < 0 && !hasEncodingPrefix(string(b)) {
+		return string(b)
+	}
+	if binaryFormat == "base64" {
+		return base64Prefix + base64.StdEncoding.EncodeToString(b)
+	}
+	return hexPrefix + hex.EncodeToString(b)
+}
+
+func isNonPrintable(r rune) bool {
+	return !unicode.IsPrint(r)
+}
+
+func hasEncodingPrefix(s string) bool {
+	return strings.HasPrefix(s, hexPrefix) || strings.HasPrefix(s, base64Prefix)
+}
+
+// parseBinary decodes input written with a hex: or base64: prefix. Other
+// input is returned unchanged.
+func parseBinary(input string) (string, error) {
+	switch {
+	case strings.HasPrefix(input, hexPrefix):
+		b, err := hex.DecodeString(strings.TrimPrefix(input, hexPrefix))
+		return string(b), err
+	case strings.HasPrefix(input, base64Prefix):
+		b, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(input, base64Prefix))
+		return string(b), err
+	default:
+		return input, nil
+	}
+}
+
+// readBinaryInput reads a key or value, decoding hex: and base64: input.
+// It asks again until the input decodes.
+func readBinaryInput(prompt string) string {
+	for {
+		input, err := parseBinary(readInput(prompt))
+		if err == nil {
+			return input
+		}
+		errorColor.Printf("✗ Invalid encoded input: %v\n", err)
+	}
+}
+
+func toBytes(keys []string) [][]byte {
+	result := make([][]byte, len(keys))
+	for i, key := range keys {
+		result[i] = []byte(key)
+	}
+	return result
+}
@@ -0,0 +1,618 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/TFMV/slatedb_demo/client"
+	pb "github.com/TFMV/slatedb_demo/proto/v2"
+	"github.com/fatih/color"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Exit codes of the subcommands.
+const (
+	exitOK          = 0
+	exitError       = 1 // the request failed
+	exitUsage       = 2 // invalid command line
+	exitNotFound    = 3 // the key, namespace or snapshot does not exist
+	exitUnavailable = 4 // the server could not be reached in time
+	exitDenied      = 5 // the token is missing or invalid, or lacks permission
+)
+
+const commandUsage = `Usage: slatedb-cli [flags] [command] [arguments]
+
+Without a command the interactive shell is started.
+
+` + commandList + `
+Flags:
+`
+
+// commandList describes the commands, for both the command line and the
+// shell.
+const commandList = `Commands:
+  put <key> <value> [--ttl duration]   store a value
+  get <key>                            print a value
+  del <key>                            delete a key
+  purge --prefix p [--dry-run]         delete the keys with a prefix
+  purge --start a --end b [--dry-run]  delete the keys in [a, b)
+  scan [--prefix p] [--limit n]        print the entries with a prefix
+  range --start a --end b [--limit n]  print the entries in [a, b)
+  stats [--prefix p]...                print database statistics
+  ns list|create <name>|drop <name>    list, create or drop namespaces
+  snapshot create [--ttl d]|release <id>
+                                       create or release a snapshot
+  export --file f [--prefix p]         write the entries with a prefix to a file
+  import --file f [--remap from=to]... store the entries of a file
+
+Export and import read and write NDJSON, or CSV for files ending in .csv.
+They also take --batch-size, --dry-run and --resume; see 'export -h'.
+
+Keys, values and prefixes may be written as hex:... or base64:... for
+binary data. Commands read and write the keys of the namespace given with
+--namespace, or of the default namespace. With --snapshot, get, scan, range
+and export read the keys as they were when the snapshot was created.
+`
+
+// cliOptions are the flags shared by all commands.
+type cliOptions struct {
+	addr       string
+	replicas   stringList
+	timeout    time.Duration
+	opTimeouts durationMap
+	retries    int
+	output     outputFormat
+	noColor    bool
+	tls        tlsOptions
+	token      secretValue
+	namespace  string
+	snapshot   string
+
+	// prefix is the working prefix set with "use" in the shell. It is
+	// prepended to the keys, prefixes and range bounds of commands.
+	prefix string
+}
+
+// loadCLIOptions returns the defaults of the flags: the built-in ones,
+// overridden by the configuration file and then by the environment.
+func loadCLIOptions() (cliOptions, error) {
+	opts := cliOptions{
+		addr:    client.DefaultAddress,
+		timeout: client.DefaultTimeout,
+		retries: client.DefaultRetryPolicy.MaxAttempts - 1,
+		output:  outputTable,
+	}
+	if err := opts.loadConfig(); err != nil {
+		return opts, err
+	}
+	if envAddr := os.Getenv("SERVER_ADDR"); envAddr != "" {
+		opts.addr = envAddr
+	}
+	if envToken := os.Getenv("SLATEDB_TOKEN"); envToken != "" {
+		opts.token = secretValue(envToken)
+	}
+	if envNamespace := os.Getenv("SLATEDB_NAMESPACE"); envNamespace != "" {
+		opts.namespace = envNamespace
+	}
+	return opts, nil
+}
+
+func (o *cliOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.addr, "addr", o.addr, "server address, also set with SERVER_ADDR")
+	fs.Var(&o.replicas, "replica", "`address` of a read-only replica that serves reads (repeatable)")
+	fs.DurationVar(&o.timeout, "timeout", o.timeout, "timeout of each request (0 for none)")
+	fs.Var(&o.opTimeouts, "op-timeout", "timeout of the requests of one RPC, as `Method=duration` (repeatable)")
+	fs.IntVar(&o.retries, "retries", o.retries, "retries of failed reads when the server is unavailable")
+	fs.Var(&o.output, "output", "output `format`: table, json, ndjson, csv or yaml")
+	fs.BoolVar(&o.noColor, "no-color", o.noColor, "disable colored output")
+	fs.StringVar(&o.tls.ca, "ca", o.tls.ca, "CA certificates that verify the server, in a PEM `file` (default the system roots)")
+	fs.StringVar(&o.tls.cert, "cert", o.tls.cert, "client certificate `file` (PEM), for servers that require one")
+	fs.StringVar(&o.tls.key, "key", o.tls.key, "private key `file` (PEM) of the client certificate")
+	fs.BoolVar(&o.tls.insecure, "insecure", o.tls.insecure, "connect without TLS")
+	fs.Var(&o.token, "token", "bearer `token` sent with every request, also set with SLATEDB_TOKEN")
+	fs.StringVar(&o.namespace, "namespace", o.namespace, "`namespace` of the keys, also set with SLATEDB_NAMESPACE (default the default namespace)")
+	fs.StringVar(&o.snapshot, "snapshot", o.snapshot, "read from the snapshot with this `id`")
+}
+
+// connect returns a client of the server of o that sends reads to the
+// replicas of o, if any. Reads from a snapshot go to the primary, which
+// holds the snapshots.
+func (o *cliOptions) connect() (*SlateDBClient, error) {
+	c, err := NewSlateDBClient(o.clientOptions()...)
+	if err != nil {
+		return nil, err
+	}
+	if o.snapshot == "" {
+		if err := c.ConnectReplicas(o.replicas, o.clientOptions()...); err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// tlsOptions are the flags that secure the connection.
+type tlsOptions struct {
+	ca, cert, key string
+	insecure      bool
+}
+
+func (o tlsOptions) validate() error {
+	if o.insecure && (o.ca != "" || o.cert != "" || o.key != "") {
+		return usagef("--insecure cannot be combined with --ca, --cert or --key")
+	}
+	if (o.cert == "") != (o.key == "") {
+		return usagef("--cert and --key must be given together")
+	}
+	return nil
+}
+
+func (o tlsOptions) clientOption() client.Option {
+	if o.insecure {
+		return client.WithInsecure()
+	}
+	return client.WithTLSFiles(o.ca, o.cert, o.key)
+}
+
+// clientOptions returns the options of a client with the address, timeouts,
+// retries, namespace and snapshot of o.
+func (o *cliOptions) clientOptions() []client.Option {
+	retry := client.DefaultRetryPolicy
+	retry.MaxAttempts = max(o.retries, 0) + 1
+
+	opts := []client.Option{
+		client.WithAddress(o.addr),
+		client.WithTimeout(o.timeout),
+		client.WithRetryPolicy(retry),
+		client.WithNamespace(o.namespace),
+		client.WithSnapshot(o.snapshot),
+		o.tls.clientOption(),
+	}
+	if o.token != "" {
+		opts = append(opts, client.WithToken(string(o.token)))
+	}
+	for method, timeout := range o.opTimeouts {
+		opts = append(opts, client.WithMethodTimeout(method, timeout))
+	}
+	return opts
+}
+
+// usageError is returned for an invalid command line.
+type usageError struct{ msg string }
+
+func (e usageError) Error() string { return e.msg }
+
+func usagef(format string, args ...interface{}) error {
+	return usageError{fmt.Sprintf(format, args...)}
+}
+
+// parseArgs parses the global flags. It returns the remaining arguments,
+// which are empty if the interactive menu should be started.
+func parseArgs(args []string, opts *cliOptions) ([]string, error) {
+	fs := flag.NewFlagSet("slatedb-cli", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), commandUsage)
+		fs.PrintDefaults()
+	}
+	opts.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	return fs.Args(), nil
+}
+
+// runCommand runs the command in args and returns the exit code.
+func runCommand(args []string, opts cliOptions) int {
+	return reportError(dispatch(args, opts))
+}
+
+// reportError prints err, if any, and returns the matching exit code.
+func reportError(err error) int {
+	if err == nil {
+		return exitOK
+	}
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+
+	var usageErr usageError
+	switch {
+	case errors.As(err, &usageErr):
+		errorColor.Fprintf(os.Stderr, "✗ %v\n", err)
+		fmt.Fprintln(os.Stderr, "Run 'slatedb-cli --help' for usage.")
+		return exitUsage
+	case errors.Is(err, client.ErrNotFound):
+		errorColor.Fprintf(os.Stderr, "✗ %s\n", status.Convert(err).Message())
+		return exitNotFound
+	}
+
+	errorColor.Fprintf(os.Stderr, "✗ Error: %v\n", err)
+	switch {
+	case errors.Is(err, client.ErrUnavailable), status.Code(err) == codes.DeadlineExceeded:
+		return exitUnavailable
+	case errors.Is(err, client.ErrUnauthenticated), errors.Is(err, client.ErrPermissionDenied):
+		return exitDenied
+	default:
+		return exitError
+	}
+}
+
+func dispatch(args []string, opts cliOptions) error {
+	fs, run, err := newCommand(args[0], &opts)
+	if err != nil {
+		return err
+	}
+
+	args, err = parseCommandArgs(fs, args[1:])
+	if err != nil {
+		return err
+	}
+	if err := opts.tls.validate(); err != nil {
+		return err
+	}
+	if opts.noColor {
+		color.NoColor = true
+	}
+
+	c, err := opts.connect()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	// Ctrl-C cancels the requests in flight.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	return run(ctx, c, args)
+}
+
+// newCommand returns the flags of the command name and the function that
+// runs it. The flags are registered into opts, and keys are taken relative
+// to opts.prefix.
+func newCommand(name string, opts *cliOptions) (*flag.FlagSet, func(context.Context, *SlateDBClient, []string) error, error) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	opts.register(fs)
+
+	var run func(context.Context, *SlateDBClient, []string) error
+	switch name {
+	case "put":
+		ttl := fs.Duration("ttl", 0, "expire the key after this long")
+		run = func(ctx context.Context, c *SlateDBClient, args []string) error {
+			if len(args) != 2 {
+				return usagef("put takes a key and a value")
+			}
+			key, value, err := parseKeyValue(args[0], args[1])
+			if err != nil {
+				return err
+			}
+			return c.Put(ctx, opts.prefix+key, value, *ttl)
+		}
+	case "get":
+		run = func(ctx context.Context, c *SlateDBClient, args []string) error {
+			if len(args) != 1 {
+				return usagef("get takes a key")
+			}
+			return runGet(ctx, c, opts.prefix, args[0], opts.output)
+		}
+	case "del", "delete":
+		run = func(ctx context.Context, c *SlateDBClient, args []string) error {
+			if len(args) != 1 {
+				return usagef("del takes a key")
+			}
+			key, err := parseKey(args[0])
+			if err != nil {
+				return err
+			}
+			return c.Delete(ctx, opts.prefix+key)
+		}
+	case "purge":
+		prefix := fs.String("prefix", "", "delete the keys with this prefix")
+		start := fs.String("start", "", "first key of the range to delete")
+		end := fs.String("end", "", "key after the end of the range to delete")
+		dryRun := fs.Bool("dry-run", false, "count the keys without deleting them")
+		run = func(ctx context.Context, c *SlateDBClient, args []string) error {
+			if len(args) != 0 {
+				return usagef("purge takes no arguments")
+			}
+			switch {
+			case *prefix != "" && *start == "" && *end == "":
+				p, err := parseBinary(*prefix)
+				if err != nil {
+					return usagef("invalid prefix: %v", err)
+				}
+				_, err = c.DeletePrefix(ctx, opts.prefix+p, *dryRun)
+				return err
+			case *prefix == "" && *start != "" && *end != "":
+				s, err := parseBinary(*start)
+				if err != nil {
+					return usagef("invalid start key: %v", err)
+				}
+				e, err := parseBinary(*end)
+				if err != nil {
+					return usagef("invalid end key: %v", err)
+				}
+				_, err = c.DeleteRange(ctx, opts.prefix+s, opts.prefix+e, *dryRun)
+				return err
+			default:
+				return usagef("purge requires either --prefix, or --start and --end")
+			}
+		}
+	case "scan":
+		prefix := fs.String("prefix", "", "key prefix")
+		limit := fs.Int("limit", 0, "maximum number of entries (0 for all)")
+		run = func(ctx context.Context, c *SlateDBClient, args []string) error {
+			if len(args) != 0 {
+				return usagef("scan takes no arguments")
+			}
+			p, err := parseBinary(*prefix)
+			if err != nil {
+				return usagef("invalid prefix: %v", err)
+			}
+			return writeEntries(os.Stdout, opts.output, c.PrefixScanStream(ctx, opts.prefix+p, int32(*limit)))
+		}
+	case "range":
+		start := fs.String("start", "", "first key of the range")
+		end := fs.String("end", "", "key after the end of the range")
+		limit := fs.Int("limit", 0, "maximum number of entries (0 for all)")
+		run = func(ctx context.Context, c *SlateDBClient, args []string) error {
+			if len(args) != 0 {
+				return usagef("range takes no arguments")
+			}
+			if *start == "" || *end == "" {
+				return usagef("range requires --start and --end")
+			}
+			s, err := parseBinary(*start)
+			if err != nil {
+				return usagef("invalid start key: %v", err)
+			}
+			e, err := parseBinary(*end)
+			if err != nil {
+				return usagef("invalid end key: %v", err)
+			}
+			return writeEntries(os.Stdout, opts.output, c.RangeScanStream(ctx, opts.prefix+s, opts.prefix+e, int32(*limit)))
+		}
+	case "stats":
+		var prefixes stringList
+		fs.Var(&prefixes, "prefix", "report keys and size for this prefix (repeatable)")
+		run = func(ctx context.Context, c *SlateDBClient, args []string) error {
+			if len(args) != 0 {
+				return usagef("stats takes no arguments")
+			}
+			decoded := make([]string, len(prefixes))
+			for i, prefix := range prefixes {
+				p, err := parseBinary(prefix)
+				if err != nil {
+					return usagef("invalid prefix: %v", err)
+				}
+				decoded[i] = opts.prefix + p
+			}
+			stats, err := c.GetStats(ctx, decoded...)
+			if err != nil {
+				return err
+			}
+			return writeStats(os.Stdout, opts.output, stats)
+		}
+	case "ns", "namespace":
+		run = func(ctx context.Context, c *SlateDBClient, args []string) error {
+			return runNamespace(ctx, c, args, opts.output)
+		}
+	case "snapshot":
+		ttl := fs.Duration("ttl", 0, "lease of the snapshot, renewed by each read (default the server default)")
+		run = func(ctx context.Context, c *SlateDBClient, args []string) error {
+			return runSnapshot(ctx, c, args, *ttl)
+		}
+	case "export":
+		prefix := fs.String("prefix", "", "key prefix")
+		var transfer transferOptions
+		transfer.register(fs, defaultExportBatchSize)
+		run = func(ctx context.Context, c *SlateDBClient, args []string) error {
+			if len(args) != 0 {
+				return usagef("export takes no arguments")
+			}
+			if err := transfer.validate(); err != nil {
+				return err
+			}
+			p, err := parseBinary(*prefix)
+			if err != nil {
+				return usagef("invalid prefix: %v", err)
+			}
+			return runExport(ctx, c, opts.prefix+p, transfer)
+		}
+	case "import":
+		var remaps remapList
+		fs.Var(&remaps, "remap", "store keys starting with `from=to` under the new prefix (repeatable)")
+		var transfer transferOptions
+		transfer.register(fs, defaultImportBatchSize)
+		run = func(ctx context.Context, c *SlateDBClient, args []string) error {
+			if len(args) != 0 {
+				return usagef("import takes no arguments")
+			}
+			if err := transfer.validate(); err != nil {
+				return err
+			}
+			return runImport(ctx, c, remaps, transfer)
+		}
+	default:
+		return nil, nil, usagef("unknown command %q", name)
+	}
+	return fs, run, nil
+}
+
+// parseCommandArgs parses the flags of a command and returns its positional
+// arguments.
+func parseCommandArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	args, err := parseInterleaved(fs, args)
+	if err != nil && !errors.Is(err, flag.ErrHelp) {
+		return nil, usagef("%v", err)
+	}
+	return args, err
+}
+
+// parseInterleaved parses fs from args, allowing flags after positional
+// arguments, and returns the positional arguments. Arguments after "--" are
+// never parsed as flags.
+func parseInterleaved(fs *flag.FlagSet, args []string) ([]string, error) {
+	fs.SetOutput(io.Discard)
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				fs.SetOutput(os.Stderr)
+				fmt.Fprintf(os.Stderr, "Usage of %s:\n", fs.Name())
+				fs.PrintDefaults()
+			}
+			return nil, err
+		}
+		rest := fs.Args()
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+// stringList is a flag that can be given several times.
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// durationMap is the repeatable --op-timeout flag, written as Method=duration
+// with the name of an RPC, for example PrefixScan=30s.
+type durationMap map[string]time.Duration
+
+func (m *durationMap) String() string {
+	var pairs []string
+	for name, d := range *m {
+		pairs = append(pairs, name+"="+d.String())
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (m *durationMap) Set(value string) error {
+	name, durationArg, ok := strings.Cut(value, "=")
+	if !ok {
+		return fmt.Errorf("expected Method=duration, got %q", value)
+	}
+	if !isUnaryMethod(name) {
+		return fmt.Errorf("unknown method %q", name)
+	}
+	d, err := time.ParseDuration(durationArg)
+	if err != nil {
+		return err
+	}
+	if *m == nil {
+		*m = make(durationMap)
+	}
+	(*m)[name] = d
+	return nil
+}
+
+func isUnaryMethod(name string) bool {
+	for _, method := range pb.SlateDB_ServiceDesc.Methods {
+		if method.MethodName == name {
+			return true
+		}
+	}
+	return false
+}
+
+func parseKey(arg string) (string, error) {
+	key, err := parseBinary(arg)
+	if err != nil {
+		return "", usagef("invalid key: %v", err)
+	}
+	if key == "" {
+		return "", usagef("key cannot be empty")
+	}
+	return key, nil
+}
+
+func parseKeyValue(keyArg, valueArg string) (string, string, error) {
+	key, err := parseKey(keyArg)
+	if err != nil {
+		return "", "", err
+	}
+	value, err := parseBinary(valueArg)
+	if err != nil {
+		return "", "", usagef("invalid value: %v", err)
+	}
+	return key, value, nil
+}
+
+func runGet(ctx context.Context, c *SlateDBClient, prefix, arg string, output outputFormat) error {
+	key, err := parseKey(arg)
+	if err != nil {
+		return err
+	}
+
+	entry, err := c.Get(ctx, prefix+key)
+	if err != nil {
+		return err
+	}
+	return writeEntry(os.Stdout, output, entry)
+}
+
+func runNamespace(ctx context.Context, c *SlateDBClient, args []string, output outputFormat) error {
+	if len(args) == 0 {
+		return usagef("ns takes list, create or drop")
+	}
+	switch {
+	case args[0] == "list" && len(args) == 1:
+		namespaces, err := c.ListNamespaces(ctx)
+		if err != nil {
+			return err
+		}
+		return writeNamespaces(os.Stdout, output, namespaces)
+	case args[0] == "create" && len(args) == 2:
+		return c.CreateNamespace(ctx, args[1])
+	case args[0] == "drop" && len(args) == 2:
+		return c.DropNamespace(ctx, args[1])
+	case args[0] == "create" || args[0] == "drop":
+		return usagef("ns %s takes a namespace name", args[0])
+	case args[0] == "list":
+		return usagef("ns list takes no arguments")
+	default:
+		return usagef("unknown ns command %q, expected list, create or drop", args[0])
+	}
+}
+
+// runSnapshot creates a snapshot and prints its ID, or releases one.
+func runSnapshot(ctx context.Context, c *SlateDBClient, args []string, ttl time.Duration) error {
+	if len(args) == 0 {
+		return usagef("snapshot takes create or release")
+	}
+	switch {
+	case args[0] == "create" && len(args) == 1:
+		snap, err := c.CreateSnapshot(ctx, ttl)
+		if err != nil {
+			return err
+		}
+		fmt.Println(snap.ID)
+		return nil
+	case args[0] == "release" && len(args) == 2:
+		return c.ReleaseSnapshot(ctx, args[1])
+	case args[0] == "create":
+		return usagef("snapshot create takes no arguments")
+	case args[0] == "release":
+		return usagef("snapshot release takes a snapshot ID")
+	default:
+		return usagef("unknown snapshot command %q, expected create or release", args[0])
+	}
+}
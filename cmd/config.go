@@ -0,0 +1,87 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v2"
+)
+
+// configFile is the name of the CLI configuration in the home directory.
+// SLATEDB_CONFIG names another file.
+const configFile = ".slatedb.yaml"
+
+// cliConfig is the configuration file of the CLI. Its entries are the
+// defaults of the flags of the same name, and replicas of --replica, so that
+// a token does not have to be typed on every command line:
+//
+//	addr: db.example.com:5423
+//	replicas: [replica1.example.com:5423]
+//	token: 9f86d081884c7d65
+//	namespace: orders
+//	ca: /etc/slatedb/ca.pem
+type cliConfig struct {
+	Addr      string   `yaml:"addr"`
+	Replicas  []string `yaml:"replicas"`
+	Token     string   `yaml:"token"`
+	Namespace string   `yaml:"namespace"`
+	CA        string   `yaml:"ca"`
+	Cert      string   `yaml:"cert"`
+	Key       string   `yaml:"key"`
+	Insecure  bool     `yaml:"insecure"`
+}
+
+// configPath returns the path of the configuration file, or "" if there is
+// no home directory.
+func configPath() string {
+	if path := os.Getenv("SLATEDB_CONFIG"); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, configFile)
+}
+
+// loadConfig applies the entries of the configuration file to o. A missing
+// file is not an error.
+func (o *cliOptions) loadConfig() error {
+	path := configPath()
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read config: %v", err)
+	}
+
+	var conf cliConfig
+	if err := yaml.UnmarshalStrict(data, &conf); err != nil {
+		return fmt.Errorf("invalid config %s: %v", path, err)
+	}
+	if conf.Addr != "" {
+		o.addr = conf.Addr
+	}
+	o.replicas = conf.Replicas
+	o.token = secretValue(conf.Token)
+	o.namespace = conf.Namespace
+	o.tls = tlsOptions{ca: conf.CA, cert: conf.Cert, key: conf.Key, insecure: conf.Insecure}
+	return nil
+}
+
+// secretValue is a string flag whose value is left out of the usage, so
+// that a token from the configuration is not printed by --help.
+type secretValue string
+
+func (s *secretValue) String() string { return "" }
+
+func (s *secretValue) Set(value string) error {
+	*s = secretValue(value)
+	return nil
+}
@@ -1,19 +1,22 @@
 package main
 
 import (
-	"bufio"
 	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"iter"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
-	pb "github.com/TFMV/slatedb_demo/proto"
+	"github.com/TFMV/slatedb_demo/client"
+	pb "github.com/TFMV/slatedb_demo/proto/v2"
 	"github.com/fatih/color"
 	"github.com/rodaine/table"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 const (
@@ -37,8 +40,8 @@ A High-Performance Key-Value Store
 	colorCyan   = "\033[36m"
 	colorWhite  = "\033[37m"
 
-	// Default server address
-	defaultServerAddr = "localhost:5423"
+	// Default number of entries per page when scanning
+	defaultPageSize = 20
 )
 
 var (
@@ -52,237 +55,429 @@ var (
 	valueColor   = color.New(color.FgHiWhite)
 )
 
+// SlateDBClient adapts client.Client to the CLI: keys and values are passed
+// as strings, and every request prints a status message.
 type SlateDBClient struct {
-	client pb.SlateDBClient
-	conn   *grpc.ClientConn
+	db *client.Client
+
+	// replicas serve the reads of keys in turn, if there are any. Writes,
+	// watches, snapshots and statistics go to db, the primary. Replicas lag
+	// behind the primary, so a read may not see a write that just returned.
+	replicas []*client.Client
+	next     *atomic.Uint64
 }
 
-func NewSlateDBClient(serverAddr string) (*SlateDBClient, error) {
-	// Set up connection to the gRPC server
-	conn, err := grpc.Dial(serverAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+func NewSlateDBClient(opts ...client.Option) (*SlateDBClient, error) {
+	db, err := client.New(opts...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to server: %v", err)
+		return nil, err
 	}
+	return &SlateDBClient{db: db, next: new(atomic.Uint64)}, nil
+}
 
-	// Create a new client
-	client := pb.NewSlateDBClient(conn)
-	return &SlateDBClient{
-		client: client,
-		conn:   conn,
-	}, nil
+// ConnectReplicas connects to the read-only replicas at addrs with opts, and
+// sends the reads of keys to them from then on.
+func (c *SlateDBClient) ConnectReplicas(addrs []string, opts ...client.Option) error {
+	for _, addr := range addrs {
+		replica, err := client.New(append(slices.Clip(opts), client.WithAddress(addr))...)
+		if err != nil {
+			return fmt.Errorf("replica %s: %w", addr, err)
+		}
+		c.replicas = append(c.replicas, replica)
+	}
+	return nil
 }
 
 func (c *SlateDBClient) Close() {
-	if c.conn != nil {
-		c.conn.Close()
+	c.db.Close()
+	for _, replica := range c.replicas {
+		replica.Close()
 	}
 }
 
-// Basic operations
-func (c *SlateDBClient) Put(key, value string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	req := &pb.PutRequest{
-		Key:   key,
-		Value: value,
+// reader returns the client of the next read: the replicas in turn, or the
+// primary if there are none.
+func (c *SlateDBClient) reader() *client.Client {
+	if len(c.replicas) == 0 {
+		return c.db
 	}
+	return c.replicas[c.next.Add(1)%uint64(len(c.replicas))]
+}
 
-	resp, err := c.client.Put(ctx, req)
-	if err != nil {
-		return err
+// with returns a client that shares the connections of c, with opts applied
+// to the primary and to every replica.
+func (c *SlateDBClient) with(opts ...client.Option) *SlateDBClient {
+	replicas := make([]*client.Client, len(c.replicas))
+	for i, replica := range c.replicas {
+		replicas[i] = replica.With(opts...)
 	}
+	return &SlateDBClient{db: c.db.With(opts...), replicas: replicas, next: c.next}
+}
 
-	successColor.Printf("✓ %s\n", resp.Message)
-	return nil
+// Primary returns a client that sends every request to the primary, for
+// reads that must see the writes before them.
+func (c *SlateDBClient) Primary() *SlateDBClient {
+	return &SlateDBClient{db: c.db, next: c.next}
 }
 
-func (c *SlateDBClient) Get(key string) (string, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+// success and info print status messages. They go to stderr so that the
+// results of a command can be piped from stdout.
+func (c *SlateDBClient) success(format string, args ...interface{}) {
+	successColor.Fprintf(os.Stderr, format, args...)
+}
 
-	req := &pb.GetRequest{
-		Key: key,
-	}
+func (c *SlateDBClient) info(format string, args ...interface{}) {
+	infoColor.Fprintf(os.Stderr, format, args...)
+}
 
-	resp, err := c.client.Get(ctx, req)
-	if err != nil {
-		return "", err
+// Basic operations
+
+// Put stores value under key. A non-zero ttl makes the key expire after that
+// long, rounded up to whole seconds.
+func (c *SlateDBClient) Put(ctx context.Context, key, value string, ttl time.Duration) error {
+	if err := c.db.Put(ctx, []byte(key), []byte(value), ttl); err != nil {
+		return err
 	}
 
-	if resp.Value == "" {
-		infoColor.Printf("ℹ %s\n", resp.Message)
-		return "", nil
+	c.success("✓ Key '%s' stored successfully\n", formatBytes([]byte(key)))
+	return nil
+}
+
+// Get returns the value of key and whether the key exists.
+// Get returns the entry of key. It fails with an error matching
+// client.ErrNotFound if the key does not exist.
+func (c *SlateDBClient) Get(ctx context.Context, key string) (*pb.KeyValue, error) {
+	entry, err := c.reader().GetEntry(ctx, []byte(key))
+	if err != nil {
+		return nil, err
 	}
 
-	successColor.Printf("✓ %s\n", resp.Message)
-	return resp.Value, nil
+	c.success("✓ Key '%s' retrieved successfully\n", formatBytes([]byte(key)))
+	return entry, nil
 }
 
-func (c *SlateDBClient) Delete(key string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+// isKeyNotFound reports whether err is the error of a missing key, as
+// opposed to that of a missing namespace or snapshot.
+func isKeyNotFound(err error) bool {
+	var e *client.Error
+	return errors.As(err, &e) && e.Reason == client.ReasonKeyNotFound
+}
 
-	req := &pb.DeleteRequest{
-		Key: key,
+func (c *SlateDBClient) Delete(ctx context.Context, key string) error {
+	if err := c.db.Delete(ctx, []byte(key)); err != nil {
+		return err
 	}
 
-	resp, err := c.client.Delete(ctx, req)
+	c.success("✓ Key '%s' deleted successfully\n", formatBytes([]byte(key)))
+	return nil
+}
+
+// Ttl reports how long key has left before it expires.
+func (c *SlateDBClient) Ttl(ctx context.Context, key string) (*pb.TtlResponse, error) {
+	resp, err := c.reader().Ttl(ctx, []byte(key))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	successColor.Printf("✓ %s\n", resp.Message)
-	return nil
+	switch {
+	case !resp.Found:
+		c.info("ℹ Key '%s' not found\n", formatBytes([]byte(key)))
+	case resp.ExpireAt == 0:
+		c.success("✓ Key '%s' does not expire\n", formatBytes([]byte(key)))
+	default:
+		c.success("✓ Key '%s' expires in %ds\n", formatBytes([]byte(key)), resp.TtlSeconds)
+	}
+	return resp, nil
 }
 
 // Batch operations
-func (c *SlateDBClient) BatchPut(entries map[string]string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
+func (c *SlateDBClient) BatchPut(ctx context.Context, entries map[string]string) error {
 	keyValues := make([]*pb.KeyValue, 0, len(entries))
 	for k, v := range entries {
 		keyValues = append(keyValues, &pb.KeyValue{
-			Key:   k,
-			Value: v,
+			Key:   []byte(k),
+			Value: []byte(v),
 		})
 	}
 
-	req := &pb.BatchPutRequest{
-		Entries: keyValues,
+	result, err := c.db.BatchPut(ctx, keyValues)
+	if err != nil {
+		return err
 	}
 
-	resp, err := c.client.BatchPut(ctx, req)
+	c.success("✓ Stored %d of %d entries (Success: %d, Failures: %d)\n",
+		result.Succeeded, len(keyValues), result.Succeeded, result.Failed)
+	return nil
+}
+
+func (c *SlateDBClient) BatchGet(ctx context.Context, keys []string) ([]*pb.KeyValue, []string, error) {
+	entries, missingKeys, err := c.reader().BatchGet(ctx, toBytes(keys))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	missing := make([]string, len(missingKeys))
+	for i, key := range missingKeys {
+		missing[i] = string(key)
+	}
+
+	c.success("✓ Retrieved %d of %d keys\n", len(entries), len(keys))
+	return entries, missing, nil
+}
+
+func (c *SlateDBClient) BatchDelete(ctx context.Context, keys []string) error {
+	result, err := c.db.BatchDelete(ctx, toBytes(keys))
 	if err != nil {
 		return err
 	}
 
-	successColor.Printf("✓ %s (Success: %d, Failures: %d)\n",
-		resp.Message, resp.SuccessCount, resp.FailureCount)
+	c.success("✓ Deleted %d of %d keys (Success: %d, Failures: %d)\n",
+		result.Succeeded, len(keys), result.Succeeded, result.Failed)
 	return nil
 }
 
-func (c *SlateDBClient) BatchGet(keys []string) (map[string]string, []string, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+// Range deletes
 
-	req := &pb.BatchGetRequest{
-		Keys: keys,
+// DeleteRange deletes every key in [start, end). With dryRun set it only
+// reports how many keys would be deleted.
+func (c *SlateDBClient) DeleteRange(ctx context.Context, start, end string, dryRun bool) (int64, error) {
+	deleted, err := c.db.DeleteRange(ctx, []byte(start), []byte(end), dryRun)
+	if err != nil {
+		return 0, err
 	}
 
-	resp, err := c.client.BatchGet(ctx, req)
+	c.success("✓ %s %d keys in range ['%s', '%s')\n",
+		deleteVerb(dryRun), deleted, formatBytes([]byte(start)), formatBytes([]byte(end)))
+	return deleted, nil
+}
+
+// DeletePrefix deletes every key with prefix. With dryRun set it only
+// reports how many keys would be deleted.
+func (c *SlateDBClient) DeletePrefix(ctx context.Context, prefix string, dryRun bool) (int64, error) {
+	deleted, err := c.db.DeletePrefix(ctx, []byte(prefix), dryRun)
 	if err != nil {
-		return nil, nil, err
+		return 0, err
 	}
 
-	results := make(map[string]string)
-	for _, kv := range resp.Entries {
-		results[kv.Key] = kv.Value
+	c.success("✓ %s %d keys with prefix '%s'\n", deleteVerb(dryRun), deleted, formatBytes([]byte(prefix)))
+	return deleted, nil
+}
+
+func deleteVerb(dryRun bool) string {
+	if dryRun {
+		return "Would delete"
 	}
+	return "Deleted"
+}
 
-	successColor.Printf("✓ %s\n", resp.Message)
-	return results, resp.MissingKeys, nil
+// Conditional operations
+
+// CompareAndSwap sets key to newValue if it currently holds expected, or if
+// it does not exist when expectAbsent is set. On a mismatch the error carries
+// the current value; see client.ConditionFailure.
+func (c *SlateDBClient) CompareAndSwap(ctx context.Context, key, expected string, expectAbsent bool, newValue string) error {
+	if err := c.db.CompareAndSwap(ctx, []byte(key), []byte(expected), expectAbsent, []byte(newValue)); err != nil {
+		return err
+	}
+
+	c.success("✓ Key '%s' swapped successfully\n", formatBytes([]byte(key)))
+	return nil
 }
 
-func (c *SlateDBClient) BatchDelete(keys []string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+func (c *SlateDBClient) PutIfAbsent(ctx context.Context, key, value string) error {
+	if err := c.db.PutIfAbsent(ctx, []byte(key), []byte(value)); err != nil {
+		return err
+	}
+
+	c.success("✓ Key '%s' stored successfully\n", formatBytes([]byte(key)))
+	return nil
+}
 
-	req := &pb.BatchDeleteRequest{
-		Keys: keys,
+func (c *SlateDBClient) DeleteIfEquals(ctx context.Context, key, expected string) error {
+	if err := c.db.DeleteIfEquals(ctx, []byte(key), []byte(expected)); err != nil {
+		return err
 	}
 
-	resp, err := c.client.BatchDelete(ctx, req)
-	if err != nil {
+	c.success("✓ Key '%s' deleted successfully\n", formatBytes([]byte(key)))
+	return nil
+}
+
+// Transactional operations
+
+// Write applies ops atomically: either all of them are applied or none are,
+// even if the server crashes while applying them.
+func (c *SlateDBClient) Write(ctx context.Context, ops []*pb.WriteOperation) error {
+	if err := c.db.Write(ctx, ops); err != nil {
 		return err
 	}
 
-	successColor.Printf("✓ %s (Success: %d, Failures: %d)\n",
-		resp.Message, resp.SuccessCount, resp.FailureCount)
+	c.success("✓ Committed %d operations\n", len(ops))
 	return nil
 }
 
 // Scanning operations
-func (c *SlateDBClient) PrefixScan(prefix string, limit int32) ([]*pb.KeyValue, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+func (c *SlateDBClient) PrefixScan(ctx context.Context, prefix string, limit int32) ([]*pb.KeyValue, error) {
+	entries, _, err := c.PrefixScanPage(ctx, prefix, limit, "")
+	return entries, err
+}
 
-	req := &pb.PrefixScanRequest{
-		Prefix: prefix,
-		Limit:  limit,
+func (c *SlateDBClient) RangeScan(ctx context.Context, startKey, endKey string, limit int32) ([]*pb.KeyValue, error) {
+	entries, _, err := c.RangeScanPage(ctx, startKey, endKey, limit, "")
+	return entries, err
+}
+
+// PrefixScanPage returns the page of entries with the given prefix that
+// starts at pageToken, along with the token of the next page.
+func (c *SlateDBClient) PrefixScanPage(ctx context.Context, prefix string, limit int32, pageToken string) ([]*pb.KeyValue, string, error) {
+	entries, next, err := c.reader().PrefixScanPage(ctx, []byte(prefix), limit, pageToken)
+	if err != nil {
+		return nil, "", err
 	}
 
-	resp, err := c.client.PrefixScan(ctx, req)
+	c.success("✓ Found %d keys with prefix '%s'\n", len(entries), formatBytes([]byte(prefix)))
+	return entries, next, nil
+}
+
+// RangeScanPage is the RangeScan counterpart of PrefixScanPage.
+func (c *SlateDBClient) RangeScanPage(ctx context.Context, startKey, endKey string, limit int32, pageToken string) ([]*pb.KeyValue, string, error) {
+	entries, next, err := c.reader().RangeScanPage(ctx, []byte(startKey), []byte(endKey), limit, pageToken)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	successColor.Printf("✓ %s\n", resp.Message)
-	return resp.Entries, nil
+	c.success("✓ Found %d keys in range ['%s', '%s')\n",
+		len(entries), formatBytes([]byte(startKey)), formatBytes([]byte(endKey)))
+	return entries, next, nil
 }
 
-func (c *SlateDBClient) RangeScan(startKey, endKey string, limit int32) ([]*pb.KeyValue, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+// WalkPrefix calls fn with every page of entries with the given prefix,
+// in key order, until the keyspace is exhausted or fn returns an error.
+func (c *SlateDBClient) WalkPrefix(ctx context.Context, prefix string, pageSize int32, fn func([]*pb.KeyValue) error) error {
+	return c.reader().WalkPrefix(ctx, []byte(prefix), pageSize, fn)
+}
 
-	req := &pb.RangeScanRequest{
-		StartKey: startKey,
-		EndKey:   endKey,
-		Limit:    limit,
-	}
+// PrefixScanStream returns an iterator over the entries with the given
+// prefix; see client.Client.PrefixScanStream.
+func (c *SlateDBClient) PrefixScanStream(ctx context.Context, prefix string, limit int32) iter.Seq2[*pb.KeyValue, error] {
+	return c.reader().PrefixScanStream(ctx, []byte(prefix), limit)
+}
+
+// RangeScanStream returns an iterator over the entries in [startKey, endKey).
+func (c *SlateDBClient) RangeScanStream(ctx context.Context, startKey, endKey string, limit int32) iter.Seq2[*pb.KeyValue, error] {
+	return c.reader().RangeScanStream(ctx, []byte(startKey), []byte(endKey), limit)
+}
+
+// Change notifications
+
+// Watch streams the events for keys with prefix; see client.Client.Watch.
+func (c *SlateDBClient) Watch(ctx context.Context, prefix string, fromSequence uint64) iter.Seq2[*pb.WatchEvent, error] {
+	return c.db.Watch(ctx, []byte(prefix), fromSequence)
+}
 
-	resp, err := c.client.RangeScan(ctx, req)
+// Statistics and monitoring
+// GetStats returns the database statistics, with a breakdown for each of
+// the given prefixes.
+func (c *SlateDBClient) GetStats(ctx context.Context, prefixes ...string) (*pb.GetStatsResponse, error) {
+	stats, err := c.db.GetStats(ctx, toBytes(prefixes)...)
 	if err != nil {
 		return nil, err
 	}
 
-	successColor.Printf("✓ %s\n", resp.Message)
-	return resp.Entries, nil
+	c.success("✓ Statistics retrieved successfully\n")
+	return stats, nil
 }
 
-// Statistics and monitoring
-func (c *SlateDBClient) GetStats() (*pb.GetStatsResponse, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+// Namespaces
+func (c *SlateDBClient) CreateNamespace(ctx context.Context, name string) error {
+	if err := c.db.CreateNamespace(ctx, name); err != nil {
+		return err
+	}
 
-	req := &pb.GetStatsRequest{}
+	c.success("✓ Namespace '%s' created successfully\n", name)
+	return nil
+}
 
-	resp, err := c.client.GetStats(ctx, req)
+func (c *SlateDBClient) ListNamespaces(ctx context.Context) ([]*pb.NamespaceStats, error) {
+	namespaces, err := c.db.ListNamespaces(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	successColor.Printf("✓ %s\n", resp.Message)
-	return resp, nil
+	c.success("✓ Found %d namespaces\n", len(namespaces))
+	return namespaces, nil
+}
+
+func (c *SlateDBClient) DropNamespace(ctx context.Context, name string) error {
+	deleted, err := c.db.DropNamespace(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	c.success("✓ Namespace '%s' dropped, %d keys deleted\n", name, deleted)
+	return nil
+}
+
+// Snapshots
+
+// CreateSnapshot creates a snapshot leased for ttl, or for the default lease
+// of the server if ttl is 0.
+func (c *SlateDBClient) CreateSnapshot(ctx context.Context, ttl time.Duration) (client.Snapshot, error) {
+	snap, err := c.db.CreateSnapshot(ctx, ttl)
+	if err != nil {
+		return client.Snapshot{}, err
+	}
+
+	c.success("✓ Snapshot '%s' created, leased until %s\n", snap.ID, snap.ExpireAt.Format(time.DateTime))
+	return snap, nil
+}
+
+func (c *SlateDBClient) ReleaseSnapshot(ctx context.Context, id string) error {
+	if err := c.db.ReleaseSnapshot(ctx, id); err != nil {
+		return err
+	}
+
+	c.success("✓ Snapshot '%s' released successfully\n", id)
+	return nil
+}
+
+// AtSnapshot returns a client that reads from the snapshot id. It shares
+// the connection of c to the primary, which holds the snapshot.
+func (c *SlateDBClient) AtSnapshot(id string) *SlateDBClient {
+	return &SlateDBClient{db: c.db.With(client.WithSnapshot(id)), next: c.next}
 }
 
 // Helper functions for the CLI
 func printBanner() {
-	titleColor.Println(banner)
+	titleColor.Print(banner, "\n")
 	fmt.Println()
 	infoColor.Println("Welcome to the SlateDB CLI Demo!")
 	fmt.Println()
 }
 
 func showMainMenu() int {
-	titleColor.Println("\n=== SlateDB CLI Demo ===\n")
+	titleColor.Print("\n=== SlateDB CLI Demo ===\n\n")
 	fmt.Println("1. Basic Operations")
 	fmt.Println("2. Batch Operations")
 	fmt.Println("3. Scanning Operations")
 	fmt.Println("4. Statistics")
 	fmt.Println("5. Run Demo Scenario")
-	fmt.Println("0. Exit")
+	fmt.Println("6. Transaction Builder")
+	fmt.Println("7. Watch Prefix (live tail)")
+	fmt.Println("0. Back to Shell")
 	fmt.Println()
 
 	return readIntInput("Choose an option: ")
 }
 
 func showBasicMenu() int {
-	titleColor.Println("\n=== Basic Operations ===\n")
+	titleColor.Print("\n=== Basic Operations ===\n\n")
 	fmt.Println("1. Put")
 	fmt.Println("2. Get")
 	fmt.Println("3. Delete")
+	fmt.Println("4. Compare and Swap")
+	fmt.Println("5. Put If Absent")
+	fmt.Println("6. Delete If Equals")
+	fmt.Println("7. Time To Live")
 	fmt.Println("0. Back to Main Menu")
 	fmt.Println()
 
@@ -290,10 +485,11 @@ func showBasicMenu() int {
 }
 
 func showBatchMenu() int {
-	titleColor.Println("\n=== Batch Operations ===\n")
+	titleColor.Print("\n=== Batch Operations ===\n\n")
 	fmt.Println("1. Batch Put")
 	fmt.Println("2. Batch Get")
 	fmt.Println("3. Batch Delete")
+	fmt.Println("4. Delete Prefix")
 	fmt.Println("0. Back to Main Menu")
 	fmt.Println()
 
@@ -301,9 +497,11 @@ func showBatchMenu() int {
 }
 
 func showScanningMenu() int {
-	titleColor.Println("\n=== Scanning Operations ===\n")
+	titleColor.Print("\n=== Scanning Operations ===\n\n")
 	fmt.Println("1. Prefix Scan")
 	fmt.Println("2. Range Scan")
+	fmt.Println("3. Prefix Scan (stream all entries)")
+	fmt.Println("4. Range Scan (stream all entries)")
 	fmt.Println("0. Back to Main Menu")
 	fmt.Println()
 
@@ -311,27 +509,38 @@ func showScanningMenu() int {
 }
 
 func readInput(prompt string) string {
-	promptColor.Printf("%s: ", prompt)
-	reader := bufio.NewReader(os.Stdin)
-	input, _ := reader.ReadString('\n')
-	return strings.TrimSpace(input)
+	return readLine(promptColor.Sprintf("%s: ", prompt))
 }
 
 func displayKeyValueTable(entries []*pb.KeyValue) {
 	headerFmt := color.New(color.FgHiCyan, color.Underline).SprintfFunc()
 	columnFmt := color.New(color.FgHiWhite).SprintfFunc()
 
-	tbl := table.New("Key", "Value")
+	tbl := table.New("Key", "Value", "Expires")
 	tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
 
 	for _, entry := range entries {
-		tbl.AddRow(entry.Key, entry.Value)
+		tbl.AddRow(formatBytes(entry.Key), formatBytes(entry.Value), formatExpiry(entry.ExpireAt))
 	}
 
 	tbl.Print()
 	fmt.Println()
 }
 
+// formatExpiry renders an expire_at in Unix seconds as the time left and the
+// local time of expiry.
+func formatExpiry(expireAt int64) string {
+	if expireAt == 0 {
+		return "never"
+	}
+	at := time.Unix(expireAt, 0)
+	left := time.Until(at).Round(time.Second)
+	if left < 0 {
+		left = 0
+	}
+	return fmt.Sprintf("in %s (%s)", left, at.Format(time.TimeOnly))
+}
+
 func displayStatsTable(stats *pb.GetStatsResponse) {
 	headerFmt := color.New(color.FgHiCyan, color.Underline).SprintfFunc()
 	columnFmt := color.New(color.FgHiWhite).SprintfFunc()
@@ -343,8 +552,90 @@ func displayStatsTable(stats *pb.GetStatsResponse) {
 	tbl.AddRow("Total Size (bytes)", stats.TotalSizeBytes)
 	tbl.AddRow("Database Path", stats.DbPath)
 
+	if ops := stats.Ops; ops != nil {
+		tbl.AddRow("Puts", ops.Puts)
+		tbl.AddRow("Gets", ops.Gets)
+		tbl.AddRow("Deletes", ops.Deletes)
+		tbl.AddRow("Scans", ops.Scans)
+		tbl.AddRow("Expirations", ops.Expirations)
+		tbl.AddRow("Server Started", time.Unix(ops.StartedAt, 0).Format(time.DateTime))
+	}
+
 	tbl.Print()
 	fmt.Println()
+
+	if len(stats.Prefixes) > 0 {
+		titleColor.Println("=== Prefixes ===")
+		prefixTbl := table.New("Prefix", "Keys", "Size (bytes)")
+		prefixTbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+		for _, prefix := range stats.Prefixes {
+			prefixTbl.AddRow(formatBytes(prefix.Prefix), prefix.Keys, prefix.SizeBytes)
+		}
+		prefixTbl.Print()
+		fmt.Println()
+	}
+
+	if len(stats.Namespaces) > 0 {
+		titleColor.Println("=== Namespaces ===")
+		displayNamespacesTable(stats.Namespaces)
+		fmt.Println()
+	}
+
+	if storage := stats.Storage; storage.GetUpdatedAt() != 0 {
+		titleColor.Println("=== Storage ===")
+		storageTbl := table.New("Metric", "Value")
+		storageTbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+		storageTbl.AddRow("Manifest ID", storage.ManifestId)
+		storageTbl.AddRow("Writer Epoch", storage.WriterEpoch)
+		storageTbl.AddRow("Compactor Epoch", storage.CompactorEpoch)
+		storageTbl.AddRow("Last WAL ID", storage.WalIdLastSeen)
+		storageTbl.AddRow("Last Compacted WAL ID", storage.WalIdLastCompacted)
+		storageTbl.AddRow("Memtable Size (bytes)", storage.MemtableSizeBytes)
+		storageTbl.AddRow("L0 SSTs", storage.L0Ssts)
+		storageTbl.AddRow("Compacted Runs", storage.CompactedRuns)
+		storageTbl.AddRow("Objects", storage.ObjectCount)
+		storageTbl.AddRow("Object Bytes", storage.ObjectBytes)
+		storageTbl.AddRow("Collected At", time.Unix(storage.UpdatedAt, 0).Format(time.DateTime))
+		storageTbl.Print()
+		fmt.Println()
+	}
+
+	if leader := stats.Leader; leader.GetState() != "" {
+		titleColor.Println("=== Leader ===")
+		leaderTbl := table.New("Metric", "Value")
+		leaderTbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+		leaderTbl.AddRow("State", leader.State)
+		leaderTbl.AddRow("Instance", leader.InstanceId)
+		leaderTbl.AddRow("Lease Holder", leader.Holder)
+		if leader.WriterEpoch != 0 {
+			leaderTbl.AddRow("Writer Epoch", leader.WriterEpoch)
+		}
+		if leader.LeaseExpireAt != 0 {
+			leaderTbl.AddRow("Lease Expires", time.Unix(leader.LeaseExpireAt, 0).Format(time.DateTime))
+		}
+		leaderTbl.Print()
+		fmt.Println()
+	}
+}
+
+// displayNamespacesTable prints namespaces, naming the default namespace.
+func displayNamespacesTable(namespaces []*pb.NamespaceStats) {
+	headerFmt := color.New(color.FgHiCyan, color.Underline).SprintfFunc()
+	columnFmt := color.New(color.FgHiWhite).SprintfFunc()
+
+	tbl := table.New("Namespace", "Keys", "Size (bytes)", "Created")
+	tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+	for _, ns := range namespaces {
+		name, created := ns.Name, "-"
+		if name == "" {
+			name = "(default)"
+		}
+		if ns.CreatedAt != 0 {
+			created = time.Unix(ns.CreatedAt, 0).Format(time.DateTime)
+		}
+		tbl.AddRow(name, ns.Keys, ns.SizeBytes, created)
+	}
+	tbl.Print()
 }
 
 func handleBasicOperations(client *SlateDBClient) {
@@ -353,19 +644,20 @@ func handleBasicOperations(client *SlateDBClient) {
 
 		switch choice {
 		case 1: // Put
-			key := readInput("Enter key: ")
+			key := readBinaryInput("Enter key: ")
 			if key == "" {
 				errorColor.Println("Key cannot be empty")
 				continue
 			}
 
-			value := readInput("Enter value: ")
-			if value == "" {
-				errorColor.Println("Value cannot be empty")
+			value := readBinaryInput("Enter value: ")
+
+			ttl, ok := readTTL()
+			if !ok {
 				continue
 			}
 
-			err := client.Put(key, value)
+			err := client.Put(context.Background(), key, value, ttl)
 			if err != nil {
 				errorColor.Printf("✗ Error: %v\n", err)
 			} else {
@@ -373,34 +665,100 @@ func handleBasicOperations(client *SlateDBClient) {
 			}
 
 		case 2: // Get
-			key := readInput("Enter key: ")
+			key := readBinaryInput("Enter key: ")
 			if key == "" {
 				errorColor.Println("Key cannot be empty")
 				continue
 			}
 
-			value, err := client.Get(key)
-			if err != nil {
+			entry, err := client.Get(context.Background(), key)
+			switch {
+			case isKeyNotFound(err):
+				infoColor.Printf("ℹ Key '%s' not found\n", formatBytes([]byte(key)))
+			case err != nil:
 				errorColor.Printf("✗ Error: %v\n", err)
-			} else {
+			default:
 				successColor.Println("✓ Get operation successful")
-				fmt.Printf("Value: %s\n", value)
+				fmt.Printf("Value: %s\n", formatBytes(entry.Value))
 			}
 
 		case 3: // Delete
-			key := readInput("Enter key: ")
+			key := readBinaryInput("Enter key: ")
 			if key == "" {
 				errorColor.Println("Key cannot be empty")
 				continue
 			}
 
-			err := client.Delete(key)
+			err := client.Delete(context.Background(), key)
 			if err != nil {
 				errorColor.Printf("✗ Error: %v\n", err)
 			} else {
 				successColor.Println("✓ Delete operation successful")
 			}
 
+		case 4: // Compare and Swap
+			key := readBinaryInput("Enter key: ")
+			if key == "" {
+				errorColor.Println("Key cannot be empty")
+				continue
+			}
+
+			expected := readBinaryInput("Enter expected value (empty if the key must not exist): ")
+			value := readBinaryInput("Enter new value: ")
+
+			err := client.CompareAndSwap(context.Background(), key, expected, expected == "", value)
+			if err != nil {
+				displayConditionError(err)
+			} else {
+				successColor.Println("✓ Compare and swap successful")
+			}
+
+		case 5: // Put If Absent
+			key := readBinaryInput("Enter key: ")
+			if key == "" {
+				errorColor.Println("Key cannot be empty")
+				continue
+			}
+
+			value := readBinaryInput("Enter value: ")
+
+			err := client.PutIfAbsent(context.Background(), key, value)
+			if err != nil {
+				displayConditionError(err)
+			} else {
+				successColor.Println("✓ Put operation successful")
+			}
+
+		case 6: // Delete If Equals
+			key := readBinaryInput("Enter key: ")
+			if key == "" {
+				errorColor.Println("Key cannot be empty")
+				continue
+			}
+
+			expected := readBinaryInput("Enter expected value: ")
+
+			err := client.DeleteIfEquals(context.Background(), key, expected)
+			if err != nil {
+				displayConditionError(err)
+			} else {
+				successColor.Println("✓ Delete operation successful")
+			}
+
+		case 7: // Time To Live
+			key := readBinaryInput("Enter key: ")
+			if key == "" {
+				errorColor.Println("Key cannot be empty")
+				continue
+			}
+
+			resp, err := client.Ttl(context.Background(), key)
+			if err != nil {
+				errorColor.Printf("✗ Error: %v\n", err)
+			} else if resp.Found {
+				fmt.Printf("Expires: %s\n", formatExpiry(resp.ExpireAt))
+			}
+
 		case 0: // Back to main menu
 			return
 
@@ -410,6 +768,37 @@ func handleBasicOperations(client *SlateDBClient) {
 	}
 }
 
+// readTTL reads an optional time to live such as "30s" or "5m". Empty input
+// means the key does not expire. It returns false for invalid input.
+func readTTL() (time.Duration, bool) {
+	input := readInput("Enter TTL (e.g. 30s, 5m; empty for none)")
+	if input == "" {
+		return 0, true
+	}
+
+	ttl, err := time.ParseDuration(input)
+	if err != nil || ttl < time.Second {
+		errorColor.Println("✗ Invalid TTL. Please enter a duration of at least 1s.")
+		return 0, false
+	}
+	return ttl, true
+}
+
+// displayConditionError prints err and, for a failed condition, the value
+// the key currently holds.
+func displayConditionError(err error) {
+	errorColor.Printf("✗ Error: %v\n", err)
+
+	current := client.ConditionFailure(err)
+	switch {
+	case current == nil:
+	case current.Found:
+		infoColor.Printf("Current value: %s\n", formatBytes(current.Value))
+	default:
+		infoColor.Println("The key does not exist")
+	}
+}
+
 func handleBatchOperations(client *SlateDBClient) {
 	for {
 		choice := showBatchMenu()
@@ -426,12 +815,12 @@ func handleBatchOperations(client *SlateDBClient) {
 			}
 
 			for i := 1; i <= n; i++ {
-				key := readInput(fmt.Sprintf("Enter key %d", i))
-				value := readInput(fmt.Sprintf("Enter value %d", i))
+				key := readBinaryInput(fmt.Sprintf("Enter key %d", i))
+				value := readBinaryInput(fmt.Sprintf("Enter value %d", i))
 				entries[key] = value
 			}
 
-			err = client.BatchPut(entries)
+			err = client.BatchPut(context.Background(), entries)
 			if err != nil {
 				errorColor.Printf("✗ Error: %v\n", err)
 			}
@@ -447,11 +836,11 @@ func handleBatchOperations(client *SlateDBClient) {
 			}
 
 			for i := 1; i <= n; i++ {
-				key := readInput(fmt.Sprintf("Enter key %d", i))
+				key := readBinaryInput(fmt.Sprintf("Enter key %d", i))
 				keys = append(keys, key)
 			}
 
-			results, missing, err := client.BatchGet(keys)
+			results, missing, err := client.BatchGet(context.Background(), keys)
 			if err != nil {
 				errorColor.Printf("✗ Error: %v\n", err)
 				continue
@@ -459,17 +848,13 @@ func handleBatchOperations(client *SlateDBClient) {
 
 			if len(results) > 0 {
 				titleColor.Println("\n=== Retrieved Key-Value Pairs ===")
-				entries := make([]*pb.KeyValue, 0, len(results))
-				for k, v := range results {
-					entries = append(entries, &pb.KeyValue{Key: k, Value: v})
-				}
-				displayKeyValueTable(entries)
+				displayKeyValueTable(results)
 			}
 
 			if len(missing) > 0 {
 				infoColor.Println("\n=== Missing Keys ===")
 				for _, k := range missing {
-					fmt.Printf("- %s\n", k)
+					fmt.Printf("- %s\n", formatBytes([]byte(k)))
 				}
 				fmt.Println()
 			}
@@ -485,11 +870,33 @@ func handleBatchOperations(client *SlateDBClient) {
 			}
 
 			for i := 1; i <= n; i++ {
-				key := readInput(fmt.Sprintf("Enter key %d", i))
+				key := readBinaryInput(fmt.Sprintf("Enter key %d", i))
 				keys = append(keys, key)
 			}
 
-			err = client.BatchDelete(keys)
+			err = client.BatchDelete(context.Background(), keys)
+			if err != nil {
+				errorColor.Printf("✗ Error: %v\n", err)
+			}
+
+		case 4: // Delete Prefix
+			prefix := readBinaryInput("Enter prefix")
+			if prefix == "" {
+				errorColor.Println("✗ Prefix cannot be empty.")
+				continue
+			}
+
+			// Count the keys first, so that nothing is deleted by mistake.
+			n, err := client.DeletePrefix(context.Background(), prefix, true)
+			if err != nil {
+				errorColor.Printf("✗ Error: %v\n", err)
+				continue
+			}
+			if n == 0 || strings.ToLower(readInput("Delete them? (y/n)")) != "y" {
+				continue
+			}
+
+			_, err = client.DeletePrefix(context.Background(), prefix, false)
 			if err != nil {
 				errorColor.Printf("✗ Error: %v\n", err)
 			}
@@ -509,58 +916,55 @@ func handleScanningOperations(client *SlateDBClient) {
 
 		switch choice {
 		case 1: // Prefix Scan
-			prefix := readInput("Enter prefix")
-			limitStr := readInput("Enter limit (or leave empty for default)")
-
-			var limit int32 = 100
-			if limitStr != "" {
-				n, err := strconv.Atoi(limitStr)
-				if err != nil || n <= 0 {
-					errorColor.Println("✗ Invalid limit. Using default (100).")
-				} else {
-					limit = int32(n)
-				}
-			}
+			prefix := readBinaryInput("Enter prefix")
+			pageSize := readPageSize()
+
+			browsePages(fmt.Sprintf("Keys with Prefix '%s'", formatBytes([]byte(prefix))), func(token string) ([]*pb.KeyValue, string, error) {
+				return client.PrefixScanPage(context.Background(), prefix, pageSize, token)
+			})
+
+		case 2: // Range Scan
+			startKey := readBinaryInput("Enter start key (or leave empty for first key)")
+			endKey := readBinaryInput("Enter end key (or leave empty for last key)")
+			pageSize := readPageSize()
 
-			entries, err := client.PrefixScan(prefix, limit)
+			browsePages(fmt.Sprintf("Keys in Range ['%s', '%s']", formatBytes([]byte(startKey)), formatBytes([]byte(endKey))), func(token string) ([]*pb.KeyValue, string, error) {
+				return client.RangeScanPage(context.Background(), startKey, endKey, pageSize, token)
+			})
+
+		case 3: // Streaming Prefix Scan
+			prefix := readBinaryInput("Enter prefix")
+			limit := readLimit()
+
+			entries, err := collectEntries(client.PrefixScanStream(context.Background(), prefix, limit))
 			if err != nil {
 				errorColor.Printf("✗ Error: %v\n", err)
 				continue
 			}
 
 			if len(entries) > 0 {
-				titleColor.Printf("\n=== Keys with Prefix '%s' ===\n", prefix)
+				titleColor.Printf("\n=== Keys with Prefix '%s' ===\n", formatBytes([]byte(prefix)))
 				displayKeyValueTable(entries)
 			} else {
-				infoColor.Printf("No keys found with prefix '%s'\n", prefix)
+				infoColor.Printf("No keys found with prefix '%s'\n", formatBytes([]byte(prefix)))
 			}
 
-		case 2: // Range Scan
-			startKey := readInput("Enter start key (or leave empty for first key)")
-			endKey := readInput("Enter end key (or leave empty for last key)")
-			limitStr := readInput("Enter limit (or leave empty for default)")
-
-			var limit int32 = 100
-			if limitStr != "" {
-				n, err := strconv.Atoi(limitStr)
-				if err != nil || n <= 0 {
-					errorColor.Println("✗ Invalid limit. Using default (100).")
-				} else {
-					limit = int32(n)
-				}
-			}
+		case 4: // Streaming Range Scan
+			startKey := readBinaryInput("Enter start key (or leave empty for first key)")
+			endKey := readBinaryInput("Enter end key (or leave empty for last key)")
+			limit := readLimit()
 
-			entries, err := client.RangeScan(startKey, endKey, limit)
+			entries, err := collectEntries(client.RangeScanStream(context.Background(), startKey, endKey, limit))
 			if err != nil {
 				errorColor.Printf("✗ Error: %v\n", err)
 				continue
 			}
 
 			if len(entries) > 0 {
-				titleColor.Printf("\n=== Keys in Range ['%s', '%s'] ===\n", startKey, endKey)
+				titleColor.Printf("\n=== Keys in Range ['%s', '%s'] ===\n", formatBytes([]byte(startKey)), formatBytes([]byte(endKey)))
 				displayKeyValueTable(entries)
 			} else {
-				infoColor.Printf("No keys found in range ['%s', '%s']\n", startKey, endKey)
+				infoColor.Printf("No keys found in range ['%s', '%s']\n", formatBytes([]byte(startKey)), formatBytes([]byte(endKey)))
 			}
 
 		case 0: // Back to main menu
@@ -572,8 +976,118 @@ func handleScanningOperations(client *SlateDBClient) {
 	}
 }
 
+// browsePages shows one page of scan results at a time and lets the user
+// move forward and backward. fetch returns the page starting at a page token
+// and the token of the following page.
+func browsePages(title string, fetch func(token string) ([]*pb.KeyValue, string, error)) {
+	// tokens holds the start token of every page up to the current one.
+	tokens := []string{""}
+
+	for {
+		entries, next, err := fetch(tokens[len(tokens)-1])
+		if err != nil {
+			errorColor.Printf("✗ Error: %v\n", err)
+			return
+		}
+
+		if len(entries) == 0 && len(tokens) == 1 {
+			infoColor.Println("No keys found")
+			return
+		}
+
+		titleColor.Printf("\n=== %s (page %d) ===\n", title, len(tokens))
+		displayKeyValueTable(entries)
+
+		options := []string{}
+		if next != "" {
+			options = append(options, "[n]ext page")
+		}
+		if len(tokens) > 1 {
+			options = append(options, "[p]revious page")
+		}
+		if len(options) == 0 {
+			return
+		}
+		options = append(options, "[b]ack")
+
+		switch strings.ToLower(readInput(strings.Join(options, ", "))) {
+		case "n", "next":
+			if next == "" {
+				infoColor.Println("This is the last page")
+				continue
+			}
+			tokens = append(tokens, next)
+		case "p", "prev", "previous":
+			if len(tokens) == 1 {
+				infoColor.Println("This is the first page")
+				continue
+			}
+			tokens = tokens[:len(tokens)-1]
+		default:
+			return
+		}
+	}
+}
+
+// readPageSize asks for the number of entries per page.
+func readPageSize() int32 {
+	sizeStr := readInput("Enter page size (or leave empty for default)")
+	if sizeStr == "" {
+		return defaultPageSize
+	}
+
+	n, err := strconv.Atoi(sizeStr)
+	if err != nil || n <= 0 {
+		errorColor.Printf("✗ Invalid page size. Using default (%d).\n", defaultPageSize)
+		return defaultPageSize
+	}
+	return int32(n)
+}
+
+// readLimit asks for an optional scan limit. Zero means no limit.
+func readLimit() int32 {
+	limitStr := readInput("Enter limit (or leave empty for no limit)")
+	if limitStr == "" {
+		return 0
+	}
+
+	n, err := strconv.Atoi(limitStr)
+	if err != nil || n <= 0 {
+		errorColor.Println("✗ Invalid limit. Scanning without a limit.")
+		return 0
+	}
+	return int32(n)
+}
+
+// collectEntries drains a streaming scan into a slice.
+func collectEntries(entries iter.Seq2[*pb.KeyValue, error]) ([]*pb.KeyValue, error) {
+	var result []*pb.KeyValue
+	for entry, err := range entries {
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, entry)
+	}
+
+	successColor.Printf("✓ Received %d entries\n", len(result))
+	return result, nil
+}
+
 func handleStats(client *SlateDBClient) {
-	stats, err := client.GetStats()
+	var prefixes []string
+	input := readInput("Prefixes to break down, comma separated (empty for none)")
+	for _, prefix := range strings.Split(input, ",") {
+		prefix, err := parseBinary(strings.TrimSpace(prefix))
+		if err != nil {
+			errorColor.Printf("✗ Invalid encoded input: %v\n", err)
+			return
+		}
+		if prefix != "" {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+
+	stats, err := client.GetStats(context.Background(), prefixes...)
 	if err != nil {
 		errorColor.Printf("✗ Error: %v\n", err)
 		return
@@ -584,27 +1098,19 @@ func handleStats(client *SlateDBClient) {
 }
 
 func runDemoScenario(client *SlateDBClient) {
-	titleColor.Println("\n=== Running Demo Scenario ===\n")
+	titleColor.Print("\n=== Running Demo Scenario ===\n\n")
+
+	// The demo reads what it just wrote, which replicas may not have yet.
+	client = client.Primary()
 
 	// Step 1: Clear any existing data
 	infoColor.Println("Step 1: Clearing existing demo data...")
-	prefixEntries, err := client.PrefixScan("demo:", 100)
+	_, err := client.DeletePrefix(context.Background(), "demo:", false)
 	if err != nil {
-		errorColor.Printf("✗ Error scanning for demo data: %v\n", err)
+		errorColor.Printf("✗ Error clearing demo data: %v\n", err)
 		return
 	}
 
-	if len(prefixEntries) > 0 {
-		keys := make([]string, len(prefixEntries))
-		for i, entry := range prefixEntries {
-			keys[i] = entry.Key
-		}
-		if err := client.BatchDelete(keys); err != nil {
-			errorColor.Printf("✗ Error deleting demo data: %v\n", err)
-			return
-		}
-	}
-
 	// Step 2: Insert some data
 	infoColor.Println("\nStep 2: Inserting sample data...")
 	demoData := map[string]string{
@@ -616,25 +1122,40 @@ func runDemoScenario(client *SlateDBClient) {
 		"demo:order:1":   `{"id": 1, "user_id": 1, "product_id": 1, "quantity": 1}`,
 		"demo:order:2":   `{"id": 2, "user_id": 2, "product_id": 2, "quantity": 2}`,
 	}
-	if err := client.BatchPut(demoData); err != nil {
+	if err := client.BatchPut(context.Background(), demoData); err != nil {
 		errorColor.Printf("✗ Error inserting sample data: %v\n", err)
 		return
 	}
 
 	// Step 3: Retrieve and display all data
-	infoColor.Println("\nStep 3: Retrieving all data with prefix scan...")
+	infoColor.Println("\nStep 3: Retrieving all data with a paginated prefix scan...")
 	time.Sleep(1 * time.Second)
-	allEntries, err := client.PrefixScan("demo:", 100)
+	page := 0
+	err = client.WalkPrefix(context.Background(), "demo:", 4, func(entries []*pb.KeyValue) error {
+		page++
+		titleColor.Printf("\n=== Page %d ===\n", page)
+		displayKeyValueTable(entries)
+		return nil
+	})
 	if err != nil {
 		errorColor.Printf("✗ Error retrieving all data: %v\n", err)
 		return
 	}
-	displayKeyValueTable(allEntries)
+
+	// Steps 4 to 6 read from a snapshot, so that they see the same state
+	// of the database even if it is written meanwhile.
+	infoColor.Println("\nTaking a snapshot for steps 4 to 6...")
+	snap, err := client.CreateSnapshot(context.Background(), time.Minute)
+	if err != nil {
+		errorColor.Printf("✗ Error creating snapshot: %v\n", err)
+		return
+	}
+	view := client.AtSnapshot(snap.ID)
 
 	// Step 4: Retrieve users
 	infoColor.Println("\nStep 4: Retrieving only users...")
 	time.Sleep(1 * time.Second)
-	userEntries, err := client.PrefixScan("demo:user:", 100)
+	userEntries, err := view.PrefixScan(context.Background(), "demo:user:", 100)
 	if err != nil {
 		errorColor.Printf("✗ Error retrieving users: %v\n", err)
 		return
@@ -644,7 +1165,7 @@ func runDemoScenario(client *SlateDBClient) {
 	// Step 5: Retrieve products
 	infoColor.Println("\nStep 5: Retrieving only products...")
 	time.Sleep(1 * time.Second)
-	productEntries, err := client.PrefixScan("demo:product:", 100)
+	productEntries, err := view.PrefixScan(context.Background(), "demo:product:", 100)
 	if err != nil {
 		errorColor.Printf("✗ Error retrieving products: %v\n", err)
 		return
@@ -654,17 +1175,22 @@ func runDemoScenario(client *SlateDBClient) {
 	// Step 6: Retrieve orders
 	infoColor.Println("\nStep 6: Retrieving only orders...")
 	time.Sleep(1 * time.Second)
-	orderEntries, err := client.PrefixScan("demo:order:", 100)
+	orderEntries, err := view.PrefixScan(context.Background(), "demo:order:", 100)
 	if err != nil {
 		errorColor.Printf("✗ Error retrieving orders: %v\n", err)
 		return
 	}
 	displayKeyValueTable(orderEntries)
 
+	if err := client.ReleaseSnapshot(context.Background(), snap.ID); err != nil {
+		errorColor.Printf("✗ Error releasing snapshot: %v\n", err)
+		return
+	}
+
 	// Step 7: Get statistics
 	infoColor.Println("\nStep 7: Getting database statistics...")
 	time.Sleep(1 * time.Second)
-	stats, err := client.GetStats()
+	stats, err := client.GetStats(context.Background(), "demo:user:", "demo:product:", "demo:order:")
 	if err != nil {
 		errorColor.Printf("✗ Error getting stats: %v\n", err)
 	} else if stats != nil {
@@ -676,7 +1202,7 @@ func runDemoScenario(client *SlateDBClient) {
 	// Step 8: Delete a user
 	infoColor.Println("\nStep 8: Deleting user 2...")
 	time.Sleep(1 * time.Second)
-	if err := client.Delete("demo:user:2"); err != nil {
+	if err := client.Delete(context.Background(), "demo:user:2"); err != nil {
 		errorColor.Printf("✗ Error deleting user: %v\n", err)
 		return
 	}
@@ -684,7 +1210,7 @@ func runDemoScenario(client *SlateDBClient) {
 	// Step 9: Verify deletion
 	infoColor.Println("\nStep 9: Verifying deletion with batch get...")
 	time.Sleep(1 * time.Second)
-	results, missing, err := client.BatchGet([]string{"demo:user:1", "demo:user:2", "demo:user:3"})
+	results, missing, err := client.BatchGet(context.Background(), []string{"demo:user:1", "demo:user:2", "demo:user:3"})
 	if err != nil {
 		errorColor.Printf("✗ Error verifying deletion: %v\n", err)
 		return
@@ -692,17 +1218,13 @@ func runDemoScenario(client *SlateDBClient) {
 
 	if len(results) > 0 {
 		titleColor.Println("\n=== Existing Users ===")
-		entries := make([]*pb.KeyValue, 0, len(results))
-		for k, v := range results {
-			entries = append(entries, &pb.KeyValue{Key: k, Value: v})
-		}
-		displayKeyValueTable(entries)
+		displayKeyValueTable(results)
 	}
 
 	if len(missing) > 0 {
 		titleColor.Println("\n=== Missing Users ===")
 		for _, k := range missing {
-			fmt.Printf("- %s\n", k)
+			fmt.Printf("- %s\n", formatBytes([]byte(k)))
 		}
 		fmt.Println()
 	}
@@ -710,7 +1232,7 @@ func runDemoScenario(client *SlateDBClient) {
 	// Step 10: Range scan
 	infoColor.Println("\nStep 10: Performing range scan from 'demo:order:1' to 'demo:order:3'...")
 	time.Sleep(1 * time.Second)
-	rangeEntries, err := client.RangeScan("demo:order:1", "demo:order:3", 100)
+	rangeEntries, err := client.RangeScan(context.Background(), "demo:order:1", "demo:order:3", 100)
 	if err != nil {
 		errorColor.Printf("✗ Error performing range scan: %v\n", err)
 		return
@@ -721,17 +1243,36 @@ func runDemoScenario(client *SlateDBClient) {
 }
 
 func main() {
-	// Print banner
-	fmt.Println(banner)
+	opts, err := loadCLIOptions()
+	if err != nil {
+		os.Exit(reportError(err))
+	}
+	args, err := parseArgs(os.Args[1:], &opts)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(exitOK)
+	}
+	if err != nil {
+		os.Exit(exitUsage)
+	}
+	if opts.noColor {
+		color.NoColor = true
+	}
 
-	// Get server address from environment variable or use default
-	serverAddr := defaultServerAddr
-	if envAddr := os.Getenv("SERVER_ADDR"); envAddr != "" {
-		serverAddr = envAddr
+	// Run a single command if one is given
+	if len(args) > 0 {
+		os.Exit(runCommand(args, opts))
 	}
+	if err := opts.tls.validate(); err != nil {
+		os.Exit(reportError(err))
+	}
+
+	// Print banner
+	fmt.Print(banner, "\n")
+
+	serverAddr := opts.addr
 
 	// Create a new client
-	client, err := NewSlateDBClient(serverAddr)
+	client, err := opts.connect()
 	if err != nil {
 		errorColor.Printf("Failed to create SlateDB client: %v\n", err)
 		errorColor.Println("\nTroubleshooting tips:")
@@ -751,13 +1292,28 @@ func main() {
 		errorColor.Println("1. Make sure the SlateDB server is running")
 		errorColor.Println("2. Check if the server is listening on port " + strings.Split(serverAddr, ":")[1])
 		errorColor.Println("3. Verify there are no firewall rules blocking the connection")
-		errorColor.Println("4. You can set a custom server address with the SERVER_ADDR environment variable")
+		errorColor.Println("4. Connections use TLS: pass --ca for a private CA, or --insecure if the server has no TLS")
+		errorColor.Println("5. If the server requires authentication, pass --token or set token in ~/" + configFile)
+		errorColor.Println("6. You can set a custom server address with the SERVER_ADDR environment variable")
 		errorColor.Println("   Example: SERVER_ADDR=localhost:8080 go run main.go")
 		os.Exit(1)
 	}
 	successColor.Println("✓ Successfully connected to SlateDB server!")
 
-	// Main menu loop
+	sh := &shell{client: client, opts: opts}
+	console, err = newConsole(sh)
+	if err != nil {
+		errorColor.Printf("Failed to start the shell: %v\n", err)
+		os.Exit(1)
+	}
+	defer console.Close()
+
+	infoColor.Println("Type 'help' for the list of commands, or 'menu' for the interactive menu.")
+	sh.run()
+}
+
+// runMenu shows the main menu until the user goes back to the shell.
+func runMenu(client *SlateDBClient) {
 	for {
 		choice := showMainMenu()
 		switch choice {
@@ -771,8 +1327,11 @@ func main() {
 			handleStats(client)
 		case 5:
 			runDemoScenario(client)
-		case 0, 6:
-			successColor.Println("Exiting SlateDB CLI. Goodbye!")
+		case 6:
+			handleTransactionBuilder(client)
+		case 7:
+			handleWatch(client)
+		case 0:
 			return
 		default:
 			errorColor.Println("Invalid option. Please try again.")
@@ -780,18 +1339,24 @@ func main() {
 	}
 }
 
-// checkConnection verifies that the server is reachable
+// checkConnection verifies that the server and its replicas are reachable
+// and serving, with the gRPC health check.
 func checkConnection(client *SlateDBClient) error {
-	// Try to get stats as a simple ping
-	_, err := client.GetStats()
-	return err
+	if err := client.db.Health(context.Background()); err != nil {
+		return err
+	}
+	for _, replica := range client.replicas {
+		if err := replica.Health(context.Background()); err != nil {
+			return fmt.Errorf("replica: %w", err)
+		}
+	}
+	return nil
 }
 
 // readIntInput reads an integer input from the user
 func readIntInput(prompt string) int {
 	for {
-		promptColor.Print(prompt)
-		input := readInput("")
+		input := readLine(promptColor.Sprint(prompt))
 
 		// Handle empty input
 		if input == "" {
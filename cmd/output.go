@@ -0,0 +1,384 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"strconv"
+
+	pb "github.com/TFMV/slatedb_demo/proto/v2"
+	"gopkg.in/yaml.v2"
+)
+
+// outputFormat selects how commands print their results. Results always go
+// to stdout and status messages to stderr, so that results can be piped.
+type outputFormat string
+
+const (
+	outputTable  outputFormat = "table"
+	outputJSON   outputFormat = "json"
+	outputNDJSON outputFormat = "ndjson"
+	outputCSV    outputFormat = "csv"
+	outputYAML   outputFormat = "yaml"
+)
+
+func (f *outputFormat) String() string { return string(*f) }
+
+func (f *outputFormat) Set(value string) error {
+	switch format := outputFormat(value); format {
+	case outputTable, outputJSON, outputNDJSON, outputCSV, outputYAML:
+		*f = format
+		return nil
+	default:
+		return fmt.Errorf("unknown output format %q", value)
+	}
+}
+
+// entryRecord is the machine-readable form of a KeyValue. Binary data is
+// encoded with a hex: or base64: prefix, which commands accept back.
+type entryRecord struct {
+	Key      string `json:"key" yaml:"key"`
+	Value    string `json:"value" yaml:"value"`
+	ExpireAt int64  `json:"expire_at,omitempty" yaml:"expire_at,omitempty"`
+}
+
+func entryRecordOf(entry *pb.KeyValue) entryRecord {
+	return entryRecord{
+		Key:      formatBytes(entry.Key),
+		Value:    formatBytes(entry.Value),
+		ExpireAt: entry.ExpireAt,
+	}
+}
+
+var entryCSVHeader = []string{"key", "value", "expire_at"}
+
+// csv returns the CSV row of r, with an empty expire_at for keys that never
+// expire.
+func (r entryRecord) csv() []string {
+	expireAt := ""
+	if r.ExpireAt != 0 {
+		expireAt = strconv.FormatInt(r.ExpireAt, 10)
+	}
+	return []string{r.Key, r.Value, expireAt}
+}
+
+// writeEntry writes a single entry, as returned by get.
+func writeEntry(w io.Writer, format outputFormat, entry *pb.KeyValue) error {
+	record := entryRecordOf(entry)
+	switch format {
+	case outputJSON:
+		return writeJSON(w, record)
+	case outputNDJSON:
+		return json.NewEncoder(w).Encode(record)
+	case outputCSV:
+		return writeCSV(w, entryCSVHeader, [][]string{record.csv()})
+	case outputYAML:
+		return writeYAML(w, record)
+	default:
+		displayKeyValueTable([]*pb.KeyValue{entry})
+		return nil
+	}
+}
+
+// writeEntries writes the entries of a scan. NDJSON and CSV rows are written
+// as entries arrive; the other formats need all entries first.
+func writeEntries(w io.Writer, format outputFormat, entries iter.Seq2[*pb.KeyValue, error]) error {
+	switch format {
+	case outputNDJSON:
+		enc := json.NewEncoder(w)
+		for entry, err := range entries {
+			if err != nil {
+				return err
+			}
+			if err := enc.Encode(entryRecordOf(entry)); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case outputCSV:
+		cw := csv.NewWriter(w)
+		cw.Write(entryCSVHeader)
+		for entry, err := range entries {
+			if err != nil {
+				cw.Flush()
+				return err
+			}
+			cw.Write(entryRecordOf(entry).csv())
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+
+	var result []*pb.KeyValue
+	for entry, err := range entries {
+		if err != nil {
+			return err
+		}
+		result = append(result, entry)
+	}
+
+	records := make([]entryRecord, len(result))
+	for i, entry := range result {
+		records[i] = entryRecordOf(entry)
+	}
+
+	switch format {
+	case outputJSON:
+		return writeJSON(w, records)
+	case outputYAML:
+		return writeYAML(w, records)
+	default:
+		displayKeyValueTable(result)
+		return nil
+	}
+}
+
+// statsRecord is the machine-readable form of GetStatsResponse.
+type statsRecord struct {
+	TotalKeys      int64             `json:"total_keys" yaml:"total_keys"`
+	TotalSizeBytes int64             `json:"total_size_bytes" yaml:"total_size_bytes"`
+	DBPath         string            `json:"db_path" yaml:"db_path"`
+	Prefixes       []prefixRecord    `json:"prefixes,omitempty" yaml:"prefixes,omitempty"`
+	Namespaces     []namespaceRecord `json:"namespaces,omitempty" yaml:"namespaces,omitempty"`
+	Storage        storageRecord     `json:"storage" yaml:"storage"`
+	Leader         leaderRecord      `json:"leader" yaml:"leader"`
+	Ops            opsRecord         `json:"ops" yaml:"ops"`
+}
+
+// namespaceRecord is a namespace as written by stats and ns list. The
+// default namespace has an empty name.
+type namespaceRecord struct {
+	Name      string `json:"name" yaml:"name"`
+	Keys      int64  `json:"keys" yaml:"keys"`
+	SizeBytes int64  `json:"size_bytes" yaml:"size_bytes"`
+	CreatedAt int64  `json:"created_at" yaml:"created_at"`
+}
+
+func namespaceRecordOf(ns *pb.NamespaceStats) namespaceRecord {
+	return namespaceRecord{Name: ns.Name, Keys: ns.Keys, SizeBytes: ns.SizeBytes, CreatedAt: ns.CreatedAt}
+}
+
+func (r namespaceRecord) csv() []string {
+	return []string{r.Name, fmt.Sprint(r.Keys), fmt.Sprint(r.SizeBytes), fmt.Sprint(r.CreatedAt)}
+}
+
+type prefixRecord struct {
+	Prefix    string `json:"prefix" yaml:"prefix"`
+	Keys      int64  `json:"keys" yaml:"keys"`
+	SizeBytes int64  `json:"size_bytes" yaml:"size_bytes"`
+}
+
+type storageRecord struct {
+	ManifestID         uint64 `json:"manifest_id" yaml:"manifest_id"`
+	WriterEpoch        uint64 `json:"writer_epoch" yaml:"writer_epoch"`
+	CompactorEpoch     uint64 `json:"compactor_epoch" yaml:"compactor_epoch"`
+	WALIDLastCompacted uint64 `json:"wal_id_last_compacted" yaml:"wal_id_last_compacted"`
+	WALIDLastSeen      uint64 `json:"wal_id_last_seen" yaml:"wal_id_last_seen"`
+	L0SSTs             int32  `json:"l0_ssts" yaml:"l0_ssts"`
+	CompactedRuns      int32  `json:"compacted_runs" yaml:"compacted_runs"`
+	MemtableSizeBytes  int64  `json:"memtable_size_bytes" yaml:"memtable_size_bytes"`
+	ObjectCount        int64  `json:"object_count" yaml:"object_count"`
+	ObjectBytes        int64  `json:"object_bytes" yaml:"object_bytes"`
+	UpdatedAt          int64  `json:"updated_at" yaml:"updated_at"`
+}
+
+type leaderRecord struct {
+	State         string `json:"state" yaml:"state"`
+	InstanceID    string `json:"instance_id" yaml:"instance_id"`
+	Holder        string `json:"holder" yaml:"holder"`
+	WriterEpoch   uint64 `json:"writer_epoch" yaml:"writer_epoch"`
+	LeaseExpireAt int64  `json:"lease_expire_at" yaml:"lease_expire_at"`
+}
+
+type opsRecord struct {
+	Puts        int64 `json:"puts" yaml:"puts"`
+	Gets        int64 `json:"gets" yaml:"gets"`
+	Deletes     int64 `json:"deletes" yaml:"deletes"`
+	Scans       int64 `json:"scans" yaml:"scans"`
+	Expirations int64 `json:"expirations" yaml:"expirations"`
+	StartedAt   int64 `json:"started_at" yaml:"started_at"`
+}
+
+func statsRecordOf(stats *pb.GetStatsResponse) statsRecord {
+	record := statsRecord{
+		TotalKeys:      stats.TotalKeys,
+		TotalSizeBytes: stats.TotalSizeBytes,
+		DBPath:         stats.DbPath,
+	}
+	for _, prefix := range stats.Prefixes {
+		record.Prefixes = append(record.Prefixes, prefixRecord{
+			Prefix:    formatBytes(prefix.Prefix),
+			Keys:      prefix.Keys,
+			SizeBytes: prefix.SizeBytes,
+		})
+	}
+	for _, ns := range stats.Namespaces {
+		record.Namespaces = append(record.Namespaces, namespaceRecordOf(ns))
+	}
+	if storage := stats.Storage; storage != nil {
+		record.Storage = storageRecord{
+			ManifestID:         storage.ManifestId,
+			WriterEpoch:        storage.WriterEpoch,
+			CompactorEpoch:     storage.CompactorEpoch,
+			WALIDLastCompacted: storage.WalIdLastCompacted,
+			WALIDLastSeen:      storage.WalIdLastSeen,
+			L0SSTs:             storage.L0Ssts,
+			CompactedRuns:      storage.CompactedRuns,
+			MemtableSizeBytes:  storage.MemtableSizeBytes,
+			ObjectCount:        storage.ObjectCount,
+			ObjectBytes:        storage.ObjectBytes,
+			UpdatedAt:          storage.UpdatedAt,
+		}
+	}
+	if leader := stats.Leader; leader != nil {
+		record.Leader = leaderRecord{
+			State:         leader.State,
+			InstanceID:    leader.InstanceId,
+			Holder:        leader.Holder,
+			WriterEpoch:   leader.WriterEpoch,
+			LeaseExpireAt: leader.LeaseExpireAt,
+		}
+	}
+	if ops := stats.Ops; ops != nil {
+		record.Ops = opsRecord{
+			Puts:        ops.Puts,
+			Gets:        ops.Gets,
+			Deletes:     ops.Deletes,
+			Scans:       ops.Scans,
+			Expirations: ops.Expirations,
+			StartedAt:   ops.StartedAt,
+		}
+	}
+	return record
+}
+
+// csv flattens the statistics into metric and value rows. Nested fields are
+// named like "storage.l0_ssts", prefixes like "prefix.demo:user:.keys" and
+// namespaces like "namespace.orders.keys", or "namespace..keys" for the
+// default namespace.
+func (r statsRecord) csv() [][]string {
+	row := func(metric string, value interface{}) []string {
+		return []string{metric, fmt.Sprint(value)}
+	}
+	rows := [][]string{
+		row("total_keys", r.TotalKeys),
+		row("total_size_bytes", r.TotalSizeBytes),
+		row("db_path", r.DBPath),
+	}
+	for _, prefix := range r.Prefixes {
+		rows = append(rows,
+			row("prefix."+prefix.Prefix+".keys", prefix.Keys),
+			row("prefix."+prefix.Prefix+".size_bytes", prefix.SizeBytes))
+	}
+	for _, ns := range r.Namespaces {
+		rows = append(rows,
+			row("namespace."+ns.Name+".keys", ns.Keys),
+			row("namespace."+ns.Name+".size_bytes", ns.SizeBytes))
+	}
+	s := r.Storage
+	rows = append(rows,
+		row("storage.manifest_id", s.ManifestID),
+		row("storage.writer_epoch", s.WriterEpoch),
+		row("storage.compactor_epoch", s.CompactorEpoch),
+		row("storage.wal_id_last_compacted", s.WALIDLastCompacted),
+		row("storage.wal_id_last_seen", s.WALIDLastSeen),
+		row("storage.l0_ssts", s.L0SSTs),
+		row("storage.compacted_runs", s.CompactedRuns),
+		row("storage.memtable_size_bytes", s.MemtableSizeBytes),
+		row("storage.object_count", s.ObjectCount),
+		row("storage.object_bytes", s.ObjectBytes),
+		row("storage.updated_at", s.UpdatedAt))
+	l := r.Leader
+	rows = append(rows,
+		row("leader.state", l.State),
+		row("leader.instance_id", l.InstanceID),
+		row("leader.holder", l.Holder),
+		row("leader.writer_epoch", l.WriterEpoch),
+		row("leader.lease_expire_at", l.LeaseExpireAt))
+	o := r.Ops
+	rows = append(rows,
+		row("ops.puts", o.Puts),
+		row("ops.gets", o.Gets),
+		row("ops.deletes", o.Deletes),
+		row("ops.scans", o.Scans),
+		row("ops.expirations", o.Expirations),
+		row("ops.started_at", o.StartedAt))
+	return rows
+}
+
+func writeStats(w io.Writer, format outputFormat, stats *pb.GetStatsResponse) error {
+	record := statsRecordOf(stats)
+	switch format {
+	case outputJSON:
+		return writeJSON(w, record)
+	case outputNDJSON:
+		return json.NewEncoder(w).Encode(record)
+	case outputCSV:
+		return writeCSV(w, []string{"metric", "value"}, record.csv())
+	case outputYAML:
+		return writeYAML(w, record)
+	default:
+		displayStatsTable(stats)
+		return nil
+	}
+}
+
+func writeNamespaces(w io.Writer, format outputFormat, namespaces []*pb.NamespaceStats) error {
+	records := make([]namespaceRecord, len(namespaces))
+	for i, ns := range namespaces {
+		records[i] = namespaceRecordOf(ns)
+	}
+	switch format {
+	case outputJSON:
+		return writeJSON(w, records)
+	case outputNDJSON:
+		enc := json.NewEncoder(w)
+		for _, record := range records {
+			if err := enc.Encode(record); err != nil {
+				return err
+			}
+		}
+		return nil
+	case outputCSV:
+		rows := make([][]string, len(records))
+		for i, record := range records {
+			rows[i] = record.csv()
+		}
+		return writeCSV(w, []string{"name", "keys", "size_bytes", "created_at"}, rows)
+	case outputYAML:
+		return writeYAML(w, records)
+	default:
+		if len(namespaces) == 0 {
+			infoColor.Fprintln(os.Stderr, "ℹ No namespaces")
+			return nil
+		}
+		displayNamespacesTable(namespaces)
+		return nil
+	}
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func writeCSV(w io.Writer, header []string, rows [][]string) error {
+	cw := csv.NewWriter(w)
+	cw.Write(header)
+	cw.WriteAll(rows)
+	return cw.Error()
+}
+
+func writeYAML(w io.Writer, v interface{}) error {
+	out, err := yaml.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-isatty"
+)
+
+const (
+	progressWidth    = 30
+	progressInterval = 100 * time.Millisecond
+)
+
+// progressBar draws the progress of a long-running command on stderr. It
+// draws nothing when stderr is not a terminal, so that logs stay clean.
+type progressBar struct {
+	w       io.Writer
+	label   string
+	total   int64 // zero if unknown
+	enabled bool
+	drawn   time.Time
+}
+
+func newProgressBar(label string, total int64) *progressBar {
+	return &progressBar{
+		w:       os.Stderr,
+		label:   label,
+		total:   total,
+		enabled: isatty.IsTerminal(os.Stderr.Fd()),
+	}
+}
+
+// update redraws the bar with done out of total, and the number of entries
+// processed so far. Redraws are limited to one per progressInterval.
+func (p *progressBar) update(done, entries int64) {
+	if !p.enabled || time.Since(p.drawn) < progressInterval {
+		return
+	}
+	p.drawn = time.Now()
+	p.draw(done, entries)
+}
+
+// finish draws the final state and ends the line.
+func (p *progressBar) finish(done, entries int64) {
+	if !p.enabled {
+		return
+	}
+	p.draw(done, entries)
+	fmt.Fprintln(p.w)
+}
+
+func (p *progressBar) draw(done, entries int64) {
+	if p.total <= 0 {
+		fmt.Fprintf(p.w, "\r%s %d entries", p.label, entries)
+		return
+	}
+
+	// The total is an estimate when the keyspace changes during an export.
+	fraction := min(float64(done)/float64(p.total), 1)
+	filled := int(fraction * progressWidth)
+	fmt.Fprintf(p.w, "\r%s [%s%s] %3.0f%% %d entries",
+		p.label, strings.Repeat("=", filled), strings.Repeat(" ", progressWidth-filled),
+		fraction*100, entries)
+}
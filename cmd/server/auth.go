@@ -0,0 +1,449 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	pbv1 "github.com/TFMV/slatedb_demo/proto"
+	pb "github.com/TFMV/slatedb_demo/proto/v2"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"gopkg.in/yaml.v2"
+)
+
+// Requests are authenticated with a bearer token in the authorization
+// header, either a static API key or an HMAC-signed JWT whose subject names
+// the principal. Each request is then authorized against ACL rules that
+// grant operations on the keys with a prefix in some namespaces.
+
+// operation is what a rule allows on its keys.
+type operation string
+
+const (
+	opRead  operation = "read"
+	opWrite operation = "write"
+	opStats operation = "stats"
+	opAdmin operation = "admin" // create and drop the namespace
+)
+
+// anyPrincipal in the principals of a rule matches every authenticated
+// principal, and anyNamespace in its namespaces every namespace.
+const (
+	anyPrincipal = "*"
+	anyNamespace = "*"
+)
+
+// authConfig is the YAML file named by AUTH_CONFIG_FILE.
+type authConfig struct {
+	APIKeys []struct {
+		Key       string `yaml:"key"`
+		Principal string `yaml:"principal"`
+	} `yaml:"api_keys"`
+
+	JWT *struct {
+		Secret     string `yaml:"secret"`
+		SecretFile string `yaml:"secret_file"`
+		Issuer     string `yaml:"issuer"`
+		Audience   string `yaml:"audience"`
+	} `yaml:"jwt"`
+
+	Rules []aclRule `yaml:"rules"`
+}
+
+// aclRule grants operations on the keys that start with one of prefixes in
+// one of namespaces. The empty prefix covers the whole namespace, and a rule
+// without namespaces applies to the default namespace only.
+type aclRule struct {
+	Principals []string    `yaml:"principals"`
+	Namespaces []string    `yaml:"namespaces"`
+	Prefixes   []string    `yaml:"prefixes"`
+	Operations []operation `yaml:"operations"`
+}
+
+// authenticator checks the tokens and permissions of requests.
+type authenticator struct {
+	logger log.Logger
+
+	apiKeys map[[sha256.Size]byte]string // SHA-256 of the key to principal
+
+	jwtSecret []byte // nil if JWTs are not accepted
+	jwtParser *jwt.Parser
+
+	rules []aclRule
+}
+
+// loadAuth reads the configuration at path.
+func loadAuth(logger log.Logger, path string) (*authenticator, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read auth config: %v", err)
+	}
+	var conf authConfig
+	if err := yaml.UnmarshalStrict(data, &conf); err != nil {
+		return nil, fmt.Errorf("invalid auth config %s: %v", path, err)
+	}
+
+	a := &authenticator{
+		logger:  logger,
+		apiKeys: make(map[[sha256.Size]byte]string),
+		rules:   conf.Rules,
+	}
+	for i, k := range conf.APIKeys {
+		if k.Key == "" || k.Principal == "" {
+			return nil, fmt.Errorf("invalid auth config %s: api key %d needs a key and a principal", path, i+1)
+		}
+		a.apiKeys[sha256.Sum256([]byte(k.Key))] = k.Principal
+	}
+
+	if j := conf.JWT; j != nil {
+		secret := []byte(j.Secret)
+		if j.SecretFile != "" {
+			if secret, err = os.ReadFile(j.SecretFile); err != nil {
+				return nil, fmt.Errorf("failed to read JWT secret: %v", err)
+			}
+			secret = []byte(strings.TrimSpace(string(secret)))
+		}
+		if len(secret) == 0 {
+			return nil, fmt.Errorf("invalid auth config %s: jwt needs a secret or a secret_file", path)
+		}
+
+		opts := []jwt.ParserOption{jwt.WithValidMethods([]string{"HS256", "HS384", "HS512"})}
+		if j.Issuer != "" {
+			opts = append(opts, jwt.WithIssuer(j.Issuer))
+		}
+		if j.Audience != "" {
+			opts = append(opts, jwt.WithAudience(j.Audience))
+		}
+		a.jwtSecret = secret
+		a.jwtParser = jwt.NewParser(opts...)
+	}
+
+	for i, rule := range conf.Rules {
+		if len(rule.Principals) == 0 || len(rule.Operations) == 0 {
+			return nil, fmt.Errorf("invalid auth config %s: rule %d needs principals and operations", path, i+1)
+		}
+		for _, op := range rule.Operations {
+			if op != opRead && op != opWrite && op != opStats && op != opAdmin {
+				return nil, fmt.Errorf("invalid auth config %s: rule %d: unknown operation %q", path, i+1, op)
+			}
+		}
+	}
+	return a, nil
+}
+
+// authenticate returns the principal of the bearer token of ctx.
+func (a *authenticator) authenticate(ctx context.Context) (string, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return "", unauthenticated(reasonMissingToken, "missing bearer token")
+	}
+	token, ok := strings.CutPrefix(values[0], "Bearer ")
+	if !ok {
+		return "", unauthenticated(reasonInvalidToken, "authorization must be a bearer token")
+	}
+
+	if principal, ok := a.apiKeys[sha256.Sum256([]byte(token))]; ok {
+		return principal, nil
+	}
+	if a.jwtParser != nil && strings.Count(token, ".") == 2 {
+		claims := jwt.RegisteredClaims{}
+		_, err := a.jwtParser.ParseWithClaims(token, &claims, func(*jwt.Token) (interface{}, error) {
+			return a.jwtSecret, nil
+		})
+		if err != nil {
+			return "", unauthenticated(reasonInvalidToken, "invalid token: %v", err)
+		}
+		if claims.Subject == "" {
+			return "", unauthenticated(reasonInvalidToken, "invalid token: no subject")
+		}
+		return claims.Subject, nil
+	}
+	return "", unauthenticated(reasonInvalidToken, "invalid token")
+}
+
+// keySpan is the keys [start, end) of a namespace touched by a request. An
+// empty end leaves the span unbounded.
+type keySpan struct {
+	op         operation
+	namespace  string
+	start, end string
+}
+
+// String describes the keys of s.
+func (s keySpan) String() string {
+	var keys string
+	switch {
+	case s.end == keyAfter(s.start):
+		keys = fmt.Sprintf("'%s'", displayKey([]byte(s.start)))
+	case s.start == "" && s.end == "":
+		keys = "all keys"
+	case s.end == prefixEnd(s.start):
+		keys = fmt.Sprintf("keys with prefix '%s'", displayKey([]byte(s.start)))
+	default:
+		keys = fmt.Sprintf("keys in ['%s', '%s')", displayKey([]byte(s.start)), displayKey([]byte(s.end)))
+	}
+	if s.namespace != "" {
+		keys += fmt.Sprintf(" in namespace '%s'", s.namespace)
+	}
+	return keys
+}
+
+func keyAccess(op operation, key []byte) keySpan {
+	return keySpan{op: op, start: string(key), end: keyAfter(string(key))}
+}
+
+func prefixAccess(op operation, prefix []byte) keySpan {
+	return keySpan{op: op, start: string(prefix), end: prefixEnd(string(prefix))}
+}
+
+// accessOf returns what a request needs to be allowed, for both versions of
+// the API. It returns false for requests it does not know.
+func accessOf(req interface{}) ([]keySpan, bool) {
+	spans, ok := keyAccessOf(req)
+	if req, hasNamespace := req.(interface{ GetNamespace() string }); hasNamespace {
+		for i := range spans {
+			spans[i].namespace = req.GetNamespace()
+		}
+	}
+	return spans, ok
+}
+
+// keyAccessOf returns the spans of a request, without their namespace.
+func keyAccessOf(req interface{}) ([]keySpan, bool) {
+	var spans []keySpan
+	switch req := req.(type) {
+	case *pb.GetRequest:
+		spans = append(spans, keyAccess(opRead, req.Key))
+	case *pb.TtlRequest:
+		spans = append(spans, keyAccess(opRead, req.Key))
+	case *pb.PutRequest:
+		spans = append(spans, keyAccess(opWrite, req.Key))
+	case *pb.DeleteRequest:
+		spans = append(spans, keyAccess(opWrite, req.Key))
+	case *pb.BatchPutRequest:
+		for _, entry := range req.Entries {
+			spans = append(spans, keyAccess(opWrite, entry.Key))
+		}
+	case *pb.BatchGetRequest:
+		for _, key := range req.Keys {
+			spans = append(spans, keyAccess(opRead, key))
+		}
+	case *pb.BatchDeleteRequest:
+		for _, key := range req.Keys {
+			spans = append(spans, keyAccess(opWrite, key))
+		}
+	case *pb.DeleteRangeRequest:
+		spans = append(spans, keySpan{op: opWrite, start: string(req.StartKey), end: string(req.EndKey)})
+	case *pb.DeletePrefixRequest:
+		spans = append(spans, prefixAccess(opWrite, req.Prefix))
+	case *pb.WriteRequest:
+		for _, op := range req.Operations {
+			switch {
+			case op.GetPut() != nil:
+				spans = append(spans, keyAccess(opWrite, op.GetPut().Key))
+			default:
+				spans = append(spans, keyAccess(opWrite, op.GetDelete()))
+			}
+		}
+	// Conditional writes report the current value when they fail.
+	case *pb.CompareAndSwapRequest:
+		spans = append(spans, keyAccess(opRead, req.Key), keyAccess(opWrite, req.Key))
+	case *pb.PutIfAbsentRequest:
+		spans = append(spans, keyAccess(opRead, req.Key), keyAccess(opWrite, req.Key))
+	case *pb.DeleteIfEqualsRequest:
+		spans = append(spans, keyAccess(opRead, req.Key), keyAccess(opWrite, req.Key))
+	case *pb.PrefixScanRequest:
+		spans = append(spans, prefixAccess(opRead, req.Prefix))
+	case *pb.RangeScanRequest:
+		spans = append(spans, keySpan{op: opRead, start: string(req.StartKey), end: string(req.EndKey)})
+	case *pb.WatchRequest:
+		spans = append(spans, prefixAccess(opRead, req.Prefix))
+	// Statistics cover the whole namespace, and those of the default
+	// namespace the whole database, including the list of namespaces.
+	case *pb.GetStatsRequest, *pbv1.GetStatsRequest, *pb.ListNamespacesRequest:
+		spans = append(spans, keySpan{op: opStats})
+	// Snapshots give no access by themselves: reads from them are checked
+	// like any other read. Only the principal that created a snapshot can
+	// release it, which ReleaseSnapshot checks.
+	case *pb.CreateSnapshotRequest, *pb.ReleaseSnapshotRequest:
+		return nil, true
+	case *pb.CreateNamespaceRequest:
+		return []keySpan{{op: opAdmin, namespace: req.Name}}, true
+	case *pb.DropNamespaceRequest:
+		return []keySpan{{op: opAdmin, namespace: req.Name}}, true
+
+	case *pbv1.GetRequest:
+		spans = append(spans, keyAccess(opRead, []byte(req.Key)))
+	case *pbv1.PutRequest:
+		spans = append(spans, keyAccess(opWrite, []byte(req.Key)))
+	case *pbv1.DeleteRequest:
+		spans = append(spans, keyAccess(opWrite, []byte(req.Key)))
+	case *pbv1.BatchPutRequest:
+		for _, entry := range req.Entries {
+			spans = append(spans, keyAccess(opWrite, []byte(entry.Key)))
+		}
+	case *pbv1.BatchGetRequest:
+		for _, key := range req.Keys {
+			spans = append(spans, keyAccess(opRead, []byte(key)))
+		}
+	case *pbv1.BatchDeleteRequest:
+		for _, key := range req.Keys {
+			spans = append(spans, keyAccess(opWrite, []byte(key)))
+		}
+	case *pbv1.PrefixScanRequest:
+		spans = append(spans, prefixAccess(opRead, []byte(req.Prefix)))
+	case *pbv1.RangeScanRequest:
+		spans = append(spans, keySpan{op: opRead, start: req.StartKey, end: req.EndKey})
+	default:
+		return nil, false
+	}
+	return spans, true
+}
+
+// allows reports whether some rule grants principal the operation of span on
+// all of its keys.
+func (a *authenticator) allows(principal string, span keySpan) bool {
+	for _, rule := range a.rules {
+		if !slices.Contains(rule.Operations, span.op) {
+			continue
+		}
+		if !slices.Contains(rule.Principals, principal) && !slices.Contains(rule.Principals, anyPrincipal) {
+			continue
+		}
+		if !rule.appliesTo(span.namespace) {
+			continue
+		}
+		for _, prefix := range rule.Prefixes {
+			if covers(prefix, span) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// appliesTo reports whether r applies to the keys of namespace.
+func (r aclRule) appliesTo(namespace string) bool {
+	if len(r.Namespaces) == 0 {
+		return namespace == ""
+	}
+	return slices.Contains(r.Namespaces, namespace) || slices.Contains(r.Namespaces, anyNamespace)
+}
+
+// covers reports whether every key of span starts with prefix.
+func covers(prefix string, span keySpan) bool {
+	if !strings.HasPrefix(span.start, prefix) {
+		return false
+	}
+	end := prefixEnd(prefix)
+	if end == "" {
+		return true
+	}
+	return span.end != "" && span.end <= end
+}
+
+// principalKey is the context key of the principal of a request.
+type principalKey struct{}
+
+// principalOf returns the principal that made the request of ctx, or "" if
+// the server does not authenticate requests.
+func principalOf(ctx context.Context) string {
+	principal, _ := ctx.Value(principalKey{}).(string)
+	return principal
+}
+
+// authorize checks the token of ctx and the permissions needed by req, and
+// returns the principal.
+func (a *authenticator) authorize(ctx context.Context, method string, req interface{}) (string, error) {
+	principal, err := a.authenticate(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	spans, ok := accessOf(req)
+	if !ok {
+		return "", accessDenied(principal, "%s is not allowed", method)
+	}
+	for _, span := range spans {
+		if !a.allows(principal, span) {
+			level.Info(a.logger).Log("msg", "permission denied", "principal", principal, "method", method,
+				"op", span.op, "keys", span)
+			switch {
+			case span.op == opAdmin:
+				return "", accessDenied(principal, "%s may not administer namespace '%s'", principal, span.namespace)
+			case span.op == opStats && span.namespace != "":
+				return "", accessDenied(principal, "%s may not read the statistics of namespace '%s'",
+					principal, span.namespace)
+			case span.op == opStats:
+				return "", accessDenied(principal, "%s may not read statistics", principal)
+			}
+			return "", accessDenied(principal, "%s may not %s %s", principal, span.op, span)
+		}
+	}
+	return principal, nil
+}
+
+// Health checks need no token, so that load balancers can probe the server,
+// and server reflection needs a valid one only: it describes the API, not
+// the data.
+
+func (a *authenticator) unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (interface{}, error) {
+	if isHealthMethod(info.FullMethod) {
+		return handler(ctx, req)
+	}
+	principal, err := a.authorize(ctx, info.FullMethod, req)
+	if err != nil {
+		return nil, err
+	}
+	return handler(context.WithValue(ctx, principalKey{}, principal), req)
+}
+
+func (a *authenticator) streamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler) error {
+	if isHealthMethod(info.FullMethod) {
+		return handler(srv, ss)
+	}
+	// Fail before the handler runs if the token is invalid, and authorize
+	// the request when the handler receives it.
+	if _, err := a.authenticate(ss.Context()); err != nil {
+		return err
+	}
+	if isReflectionMethod(info.FullMethod) {
+		return handler(srv, ss)
+	}
+	return handler(srv, &authorizedStream{ServerStream: ss, a: a, method: info.FullMethod})
+}
+
+// authorizedStream authorizes the messages received on a stream.
+type authorizedStream struct {
+	grpc.ServerStream
+	a      *authenticator
+	method string
+}
+
+func (s *authorizedStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	_, err := s.a.authorize(s.Context(), s.method, m)
+	return err
+}
+
+func unauthenticated(reason, format string, args ...interface{}) error {
+	return failure(codes.Unauthenticated, reason, nil, fmt.Sprintf(format, args...))
+}
+
+func accessDenied(principal, format string, args ...interface{}) error {
+	return failure(codes.PermissionDenied, reasonAccessDenied, map[string]string{"principal": principal},
+		fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,112 @@
+package main
+
+import "testing"
+
+func TestCovers(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefix string
+		span   keySpan
+		want   bool
+	}{
+		{"empty prefix covers a key", "", keyAccess(opRead, []byte("a")), true},
+		{"empty prefix covers everything", "", keySpan{op: opRead}, true},
+		{"key with prefix", "user:", keyAccess(opRead, []byte("user:1")), true},
+		{"key equal to prefix", "user:", keyAccess(opRead, []byte("user:")), true},
+		{"key without prefix", "user:", keyAccess(opRead, []byte("users")), false},
+		{"key shorter than prefix", "user:", keyAccess(opRead, []byte("user")), false},
+		{"same prefix", "user:", prefixAccess(opRead, []byte("user:")), true},
+		{"longer prefix", "user:", prefixAccess(opRead, []byte("user:1")), true},
+		{"shorter prefix", "user:", prefixAccess(opRead, []byte("user")), false},
+		{"sibling prefix", "user:", prefixAccess(opRead, []byte("user;")), false},
+		{"range inside", "user:", keySpan{op: opRead, start: "user:a", end: "user:z"}, true},
+		{"range up to the prefix end", "user:", keySpan{op: opRead, start: "user:a", end: "user;"}, true},
+		{"range past the prefix end", "user:", keySpan{op: opRead, start: "user:a", end: "user;a"}, false},
+		{"range starting before", "user:", keySpan{op: opRead, start: "user", end: "user:z"}, false},
+		{"unbounded range", "user:", keySpan{op: opRead, start: "user:a"}, false},
+		{"unbounded range under 0xff prefix", "\xff", keySpan{op: opRead, start: "\xff\x01"}, true},
+		{"range under 0xff prefix", "a\xff", keySpan{op: opRead, start: "a\xff", end: "b"}, true},
+		{"range past 0xff prefix", "a\xff", keySpan{op: opRead, start: "a\xff", end: "b\x00"}, false},
+		{"whole keyspace", "a", keySpan{op: opRead}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := covers(tt.prefix, tt.span); got != tt.want {
+				t.Errorf("covers(%q, %+v) = %v, want %v", tt.prefix, tt.span, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllows(t *testing.T) {
+	a := &authenticator{rules: []aclRule{
+		{Principals: []string{"alice"}, Prefixes: []string{"user:"}, Operations: []operation{opRead, opWrite}},
+		{Principals: []string{"bob"}, Prefixes: []string{"user:", "order:"}, Operations: []operation{opRead}},
+		{Principals: []string{"carol"}, Namespaces: []string{"tenant-a"}, Prefixes: []string{""},
+			Operations: []operation{opRead, opWrite, opStats, opAdmin}},
+		{Principals: []string{"dave"}, Namespaces: []string{anyNamespace}, Prefixes: []string{"cache:"},
+			Operations: []operation{opRead}},
+		{Principals: []string{anyPrincipal}, Prefixes: []string{"public:"}, Operations: []operation{opRead}},
+		{Principals: []string{"erin"}, Prefixes: []string{""}, Operations: []operation{opStats}},
+	}}
+
+	inNamespace := func(ns string, span keySpan) keySpan {
+		span.namespace = ns
+		return span
+	}
+
+	tests := []struct {
+		name      string
+		principal string
+		span      keySpan
+		want      bool
+	}{
+		{"read own prefix", "alice", keyAccess(opRead, []byte("user:1")), true},
+		{"write own prefix", "alice", keyAccess(opWrite, []byte("user:1")), true},
+		{"outside prefix", "alice", keyAccess(opRead, []byte("order:1")), false},
+		{"prefix boundary", "alice", keyAccess(opRead, []byte("user")), false},
+		{"scan own prefix", "alice", prefixAccess(opRead, []byte("user:")), true},
+		{"scan wider prefix", "alice", prefixAccess(opRead, []byte("u")), false},
+		{"operation not granted", "bob", keyAccess(opWrite, []byte("user:1")), false},
+		{"second prefix", "bob", keyAccess(opRead, []byte("order:1")), true},
+		{"range across prefixes", "bob", keySpan{op: opRead, start: "order:", end: "user;"}, false},
+		{"no stats without a rule", "alice", keySpan{op: opStats}, false},
+		{"stats rule", "erin", keySpan{op: opStats}, true},
+		{"stats rule in another namespace", "erin", inNamespace("tenant-a", keySpan{op: opStats}), false},
+		{"unknown principal", "mallory", keyAccess(opRead, []byte("user:1")), false},
+		{"any principal", "mallory", keyAccess(opRead, []byte("public:x")), true},
+		{"any principal cannot write", "mallory", keyAccess(opWrite, []byte("public:x")), false},
+		{"default namespace rule in a namespace", "alice", inNamespace("tenant-a", keyAccess(opRead, []byte("user:1"))), false},
+		{"namespace rule", "carol", inNamespace("tenant-a", keyAccess(opWrite, []byte("anything"))), true},
+		{"namespace rule, other namespace", "carol", inNamespace("tenant-b", keyAccess(opRead, []byte("anything"))), false},
+		{"namespace rule, default namespace", "carol", keyAccess(opRead, []byte("anything")), false},
+		{"admin of namespace", "carol", keySpan{op: opAdmin, namespace: "tenant-a"}, true},
+		{"admin of other namespace", "carol", keySpan{op: opAdmin, namespace: "tenant-b"}, false},
+		{"any namespace", "dave", inNamespace("tenant-b", keyAccess(opRead, []byte("cache:1"))), true},
+		{"any namespace includes default", "dave", keyAccess(opRead, []byte("cache:1")), true},
+		{"any namespace keeps prefixes", "dave", inNamespace("tenant-b", keyAccess(opRead, []byte("user:1"))), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := a.allows(tt.principal, tt.span); got != tt.want {
+				t.Errorf("allows(%q, %+v) = %v, want %v", tt.principal, tt.span, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllowsDeniesByDefault(t *testing.T) {
+	a := &authenticator{}
+	spans := []keySpan{
+		keyAccess(opRead, []byte("a")),
+		keyAccess(opWrite, []byte("a")),
+		{op: opRead},
+		{op: opStats},
+		{op: opAdmin, namespace: "tenant-a"},
+	}
+	for _, span := range spans {
+		if a.allows("alice", span) {
+			t.Errorf("allows(alice, %+v) with no rules = true, want false", span)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/thanos-io/objstore"
+	"github.com/thanos-io/objstore/providers/azure"
+	"github.com/thanos-io/objstore/providers/filesystem"
+	"github.com/thanos-io/objstore/providers/gcs"
+	"github.com/thanos-io/objstore/providers/s3"
+	"gopkg.in/yaml.v2"
+)
+
+type bucketProvider string
+
+const (
+	providerGCS        bucketProvider = "GCS"
+	providerS3         bucketProvider = "S3"
+	providerAzure      bucketProvider = "AZURE"
+	providerFilesystem bucketProvider = "FILESYSTEM"
+	providerInMemory   bucketProvider = "IN-MEMORY"
+)
+
+// bucketConfig is a thanos-style object storage configuration:
+//
+//	type: FILESYSTEM
+//	config:
+//	  directory: /var/lib/slatedb
+//	prefix: demo
+//
+// The config block is passed unchanged to the selected thanos provider.
+type bucketConfig struct {
+	Type   bucketProvider `yaml:"type"`
+	Config interface{}    `yaml:"config"`
+	Prefix string         `yaml:"prefix"`
+}
+
+// loadBucketConfig returns the bucket configuration from the environment.
+// BUCKET_CONFIG holds inline YAML and BUCKET_CONFIG_FILE points to a YAML
+// file. Without either, BUCKET_NAME selects a GCS bucket.
+func loadBucketConfig() ([]byte, error) {
+	if conf := os.Getenv("BUCKET_CONFIG"); conf != "" {
+		return []byte(conf), nil
+	}
+	if file := os.Getenv("BUCKET_CONFIG_FILE"); file != "" {
+		conf, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bucket config: %v", err)
+		}
+		return conf, nil
+	}
+	if name := os.Getenv("BUCKET_NAME"); name != "" {
+		return yaml.Marshal(bucketConfig{
+			Type:   providerGCS,
+			Config: map[string]string{"bucket": name},
+		})
+	}
+	return nil, errors.New("one of BUCKET_CONFIG, BUCKET_CONFIG_FILE or BUCKET_NAME must be set")
+}
+
+// newBucket creates the bucket described by the YAML configuration conf.
+func newBucket(ctx context.Context, logger log.Logger, conf []byte) (objstore.Bucket, bucketProvider, error) {
+	var bc bucketConfig
+	if err := yaml.UnmarshalStrict(conf, &bc); err != nil {
+		return nil, "", fmt.Errorf("failed to parse bucket config: %v", err)
+	}
+
+	// Each provider parses its own section of the configuration.
+	providerConf, err := yaml.Marshal(bc.Config)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to marshal bucket config: %v", err)
+	}
+
+	provider := bucketProvider(strings.ToUpper(string(bc.Type)))
+
+	var bucket objstore.Bucket
+	switch provider {
+	case providerGCS:
+		bucket, err = gcs.NewBucket(ctx, logger, providerConf, "slatedb")
+	case providerS3:
+		bucket, err = s3.NewBucket(logger, providerConf, "slatedb")
+	case providerAzure:
+		bucket, err = azure.NewBucket(logger, providerConf, "slatedb")
+	case providerFilesystem:
+		bucket, err = filesystem.NewBucketFromConfig(providerConf)
+	case providerInMemory:
+		bucket = objstore.NewInMemBucket()
+	default:
+		return nil, "", fmt.Errorf("unsupported bucket type %q", bc.Type)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create %s bucket: %v", provider, err)
+	}
+
+	if bc.Prefix != "" {
+		bucket = objstore.NewPrefixedBucket(bucket, bc.Prefix)
+	}
+	return bucket, provider, nil
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/TFMV/slatedb_demo/proto/v2"
+	"github.com/thanos-io/objstore"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// wantConditionFailed checks that err is the FAILED_PRECONDITION error of a
+// conditional write, with the current state of key as its detail.
+func wantConditionFailed(t *testing.T, err error, key string, found bool, value string) {
+	t.Helper()
+	st := status.Convert(err)
+	if st.Code() != codes.FailedPrecondition {
+		t.Fatalf("error = %v, want FAILED_PRECONDITION", err)
+	}
+	var current *pb.CurrentValue
+	reason := ""
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *pb.CurrentValue:
+			current = d
+		case *errdetails.ErrorInfo:
+			reason = d.Reason
+		}
+	}
+	if reason != reasonConditionFailed {
+		t.Errorf("error reason = %q, want %q", reason, reasonConditionFailed)
+	}
+	if current == nil {
+		t.Fatalf("error %v has no CurrentValue detail", err)
+	}
+	if string(current.Key) != key || current.Found != found || string(current.Value) != value {
+		t.Errorf("current value = {%q, found %v, %q}, want {%q, found %v, %q}",
+			current.Key, current.Found, current.Value, key, found, value)
+	}
+}
+
+func TestConditionalWrites(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name  string
+		write func(s *server) error
+
+		// wantFailed is set if the condition does not hold, and wantFound
+		// and wantCurrent then give the current value of key in the error.
+		wantFailed  bool
+		wantFound   bool
+		wantCurrent string
+		key         string
+		wantValue   []byte // of key after the write, nil if it does not exist
+	}{
+		{
+			name: "swap",
+			write: func(s *server) error {
+				_, err := s.CompareAndSwap(ctx, &pb.CompareAndSwapRequest{Key: []byte("k"), ExpectedValue: []byte("old"), NewValue: []byte("new")})
+				return err
+			},
+			key:       "k",
+			wantValue: []byte("new"),
+		},
+		{
+			name: "swap with a mismatch",
+			write: func(s *server) error {
+				_, err := s.CompareAndSwap(ctx, &pb.CompareAndSwapRequest{Key: []byte("k"), ExpectedValue: []byte("other"), NewValue: []byte("new")})
+				return err
+			},
+			wantFailed:  true,
+			wantFound:   true,
+			wantCurrent: "old",
+			key:         "k",
+			wantValue:   []byte("old"),
+		},
+		{
+			name: "swap of a missing key",
+			write: func(s *server) error {
+				_, err := s.CompareAndSwap(ctx, &pb.CompareAndSwapRequest{Key: []byte("missing"), ExpectedValue: []byte(""), NewValue: []byte("new")})
+				return err
+			},
+			wantFailed: true,
+			key:        "missing",
+		},
+		{
+			name: "expect_absent on an existing key",
+			write: func(s *server) error {
+				_, err := s.CompareAndSwap(ctx, &pb.CompareAndSwapRequest{Key: []byte("k"), ExpectAbsent: true, NewValue: []byte("new")})
+				return err
+			},
+			wantFailed:  true,
+			wantFound:   true,
+			wantCurrent: "old",
+			key:         "k",
+			wantValue:   []byte("old"),
+		},
+		{
+			name: "expect_absent on a missing key",
+			write: func(s *server) error {
+				_, err := s.CompareAndSwap(ctx, &pb.CompareAndSwapRequest{Key: []byte("missing"), ExpectAbsent: true, NewValue: []byte("new")})
+				return err
+			},
+			key:       "missing",
+			wantValue: []byte("new"),
+		},
+		{
+			name: "put if absent on an existing key",
+			write: func(s *server) error {
+				_, err := s.PutIfAbsent(ctx, &pb.PutIfAbsentRequest{Key: []byte("k"), Value: []byte("new")})
+				return err
+			},
+			wantFailed:  true,
+			wantFound:   true,
+			wantCurrent: "old",
+			key:         "k",
+			wantValue:   []byte("old"),
+		},
+		{
+			name: "put if absent on a missing key",
+			write: func(s *server) error {
+				_, err := s.PutIfAbsent(ctx, &pb.PutIfAbsentRequest{Key: []byte("missing"), Value: []byte("new")})
+				return err
+			},
+			key:       "missing",
+			wantValue: []byte("new"),
+		},
+		{
+			name: "delete if equals",
+			write: func(s *server) error {
+				_, err := s.DeleteIfEquals(ctx, &pb.DeleteIfEqualsRequest{Key: []byte("k"), ExpectedValue: []byte("old")})
+				return err
+			},
+			key: "k",
+		},
+		{
+			name: "delete if equals with a mismatch",
+			write: func(s *server) error {
+				_, err := s.DeleteIfEquals(ctx, &pb.DeleteIfEqualsRequest{Key: []byte("k"), ExpectedValue: []byte("other")})
+				return err
+			},
+			wantFailed:  true,
+			wantFound:   true,
+			wantCurrent: "old",
+			key:         "k",
+			wantValue:   []byte("old"),
+		},
+		{
+			name: "delete if equals of a missing key",
+			write: func(s *server) error {
+				_, err := s.DeleteIfEquals(ctx, &pb.DeleteIfEqualsRequest{Key: []byte("missing"), ExpectedValue: []byte("")})
+				return err
+			},
+			wantFailed: true,
+			key:        "missing",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := openTestServer(t, objstore.NewInMemBucket())
+			defer s.Close()
+			mustPut(t, s, "k", "old")
+
+			err := tt.write(s)
+			if tt.wantFailed {
+				wantConditionFailed(t, err, tt.key, tt.wantFound, tt.wantCurrent)
+			} else if err != nil {
+				t.Fatalf("write failed: %v", err)
+			}
+			wantValue(t, s, tt.key, tt.wantValue)
+		})
+	}
+}
+
+func TestConditionalWriteInNamespace(t *testing.T) {
+	ctx := context.Background()
+	s := openTestServer(t, objstore.NewInMemBucket())
+	defer s.Close()
+	if _, err := s.CreateNamespace(ctx, &pb.CreateNamespaceRequest{Name: "ns"}); err != nil {
+		t.Fatalf("CreateNamespace failed: %v", err)
+	}
+	mustPut(t, s, "k", "default")
+
+	// The condition reads the key of the namespace, and the detail holds
+	// the key as the client sent it.
+	_, err := s.CompareAndSwap(ctx, &pb.CompareAndSwapRequest{Key: []byte("k"), ExpectedValue: []byte("default"), NewValue: []byte("new"), Namespace: "ns"})
+	wantConditionFailed(t, err, "k", false, "")
+
+	_, err = s.PutIfAbsent(ctx, &pb.PutIfAbsentRequest{Key: []byte("k"), Value: []byte("ns"), Namespace: "ns"})
+	if err != nil {
+		t.Fatalf("PutIfAbsent failed: %v", err)
+	}
+	resp, err := s.Get(ctx, &pb.GetRequest{Key: []byte("k"), Namespace: "ns"})
+	if err != nil || string(resp.Value) != "ns" {
+		t.Errorf("Get of k in namespace ns = %v, %v; want %q", resp, err, "ns")
+	}
+	wantValue(t, s, "k", []byte("default"))
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// Errors use the canonical gRPC codes and carry google.rpc details, so that
+// clients can tell failures apart without parsing messages. INVALID_ARGUMENT
+// errors carry a BadRequest naming the invalid field; other errors, except
+// INTERNAL ones, carry an ErrorInfo whose reason names the failure.
+
+// errorDomain is the domain of the ErrorInfo details.
+const errorDomain = "slatedb.v2"
+
+// Reasons of the ErrorInfo details.
+const (
+	reasonKeyNotFound        = "KEY_NOT_FOUND"
+	reasonNamespaceNotFound  = "NAMESPACE_NOT_FOUND"
+	reasonNamespaceExists    = "NAMESPACE_EXISTS"
+	reasonSnapshotNotFound   = "SNAPSHOT_NOT_FOUND"
+	reasonTooManySnapshots   = "TOO_MANY_SNAPSHOTS"
+	reasonConditionFailed    = "CONDITION_FAILED"
+	reasonBinaryData         = "BINARY_DATA"
+	reasonMissingToken       = "MISSING_TOKEN"
+	reasonInvalidToken       = "INVALID_TOKEN"
+	reasonAccessDenied       = "ACCESS_DENIED"
+	reasonHistoryUnavailable = "HISTORY_UNAVAILABLE"
+	reasonWatcherTooSlow     = "WATCHER_TOO_SLOW"
+	reasonShuttingDown       = "SHUTTING_DOWN"
+	reasonReadOnly           = "READ_ONLY"
+	reasonFenced             = "FENCED"
+	reasonLeaseExpired       = "LEASE_EXPIRED"
+)
+
+// failure returns an error with code and message and an ErrorInfo detail
+// with reason and metadata, followed by any other details.
+func failure(code codes.Code, reason string, metadata map[string]string, msg string, details ...protoadapt.MessageV1) error {
+	st := status.New(code, msg)
+	info := &errdetails.ErrorInfo{Reason: reason, Domain: errorDomain, Metadata: metadata}
+	detailed, err := st.WithDetails(append([]protoadapt.MessageV1{info}, details...)...)
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// invalidArgument returns an INVALID_ARGUMENT error for the request field
+// with a BadRequest detail.
+func invalidArgument(field, format string, args ...interface{}) error {
+	msg := fmt.Sprintf(format, args...)
+	st := status.New(codes.InvalidArgument, msg)
+	detailed, err := st.WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: field, Description: msg}},
+	})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// invalidField returns the field named by an error of invalidArgument.
+func invalidField(err error) string {
+	for _, detail := range status.Convert(err).Details() {
+		if req, ok := detail.(*errdetails.BadRequest); ok && len(req.FieldViolations) > 0 {
+			return req.FieldViolations[0].Field
+		}
+	}
+	return ""
+}
+
+// keyNotFound returns the NOT_FOUND error of a missing key.
+func keyNotFound(ns namespace, key []byte) error {
+	return failure(codes.NotFound, reasonKeyNotFound,
+		map[string]string{"key": displayKey(key), "namespace": ns.name},
+		fmt.Sprintf("key '%s' not found", displayKey(key)))
+}
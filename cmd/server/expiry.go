@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/slatedb/slatedb-go/slatedb"
+)
+
+const (
+	// reapInterval is how often expired keys are deleted from the DB.
+	// Expired keys are hidden from reads as soon as they expire, whether or
+	// not they were deleted yet.
+	reapInterval = time.Second
+
+	// envelopeVersion is the first byte of a stored value.
+	envelopeVersion = 1
+
+	// envelopeSize is the size of the envelope in front of a stored value:
+	// the version and the expiry.
+	envelopeSize = 1 + 8
+
+	// maxExpireAt is the latest expiry that Unix nanoseconds can hold, in
+	// Unix seconds: 2262-04-11.
+	maxExpireAt = math.MaxInt64 / int64(time.Second)
+)
+
+// expiryOf converts the ttl_seconds and expire_at fields of a write to an
+// expiry in Unix nanoseconds, or 0 if neither is set. Expiries after
+// maxExpireAt are rejected rather than wrapped around.
+func expiryOf(ttlSeconds, expireAt int64) (int64, error) {
+	now := time.Now().UnixNano()
+	switch {
+	case ttlSeconds < 0 || expireAt < 0:
+		return 0, invalidArgument("ttl_seconds", "ttl_seconds and expire_at cannot be negative")
+	case ttlSeconds > 0 && expireAt > 0:
+		return 0, invalidArgument("expire_at", "only one of ttl_seconds and expire_at can be set")
+	case ttlSeconds > (math.MaxInt64-now)/int64(time.Second):
+		return 0, invalidArgument("ttl_seconds", "ttl_seconds %d expires after %s", ttlSeconds, maxExpiry())
+	case expireAt > maxExpireAt:
+		return 0, invalidArgument("expire_at", "expire_at %d is after %s", expireAt, maxExpiry())
+	case ttlSeconds > 0:
+		return now + ttlSeconds*int64(time.Second), nil
+	case expireAt > 0:
+		return expireAt * int64(time.Second), nil
+	default:
+		return 0, nil
+	}
+}
+
+// maxExpiry returns maxExpireAt for messages.
+func maxExpiry() string {
+	return time.Unix(maxExpireAt, 0).UTC().Format(time.RFC3339)
+}
+
+// expired reports whether a key with the expiry expireAt, in Unix
+// nanoseconds or 0 if it does not expire, has expired at now.
+func expired(expireAt, now int64) bool {
+	return expireAt > 0 && expireAt <= now
+}
+
+// Values are stored in an envelope that holds their expiry, so that a key
+// expires even if the index lost track of it: a version byte, then the
+// expiry in Unix nanoseconds as a big-endian int64, 0 if the key does not
+// expire, then the value.
+
+// encodeValue returns the stored form of value.
+func encodeValue(value []byte, expireAt int64) []byte {
+	stored := make([]byte, envelopeSize, envelopeSize+len(value))
+	stored[0] = envelopeVersion
+	binary.BigEndian.PutUint64(stored[1:], uint64(expireAt))
+	return append(stored, value...)
+}
+
+// decodeValue returns the value and expiry held in stored.
+func decodeValue(stored []byte) ([]byte, int64, error) {
+	if len(stored) < envelopeSize || stored[0] != envelopeVersion {
+		return nil, 0, fmt.Errorf("stored value is not in a version %d envelope", envelopeVersion)
+	}
+	return stored[envelopeSize:], int64(binary.BigEndian.Uint64(stored[1:])), nil
+}
+
+// readValue reads key from db and returns its value and expiry.
+func readValue(db *slatedb.DB, key []byte, opts slatedb.ReadOptions) ([]byte, int64, error) {
+	stored, err := db.GetWithOptions(key, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	value, expireAt, err := decodeValue(stored)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to decode key '%s': %v", displayKey(key), err)
+	}
+	return value, expireAt, nil
+}
+
+// unixSeconds converts an expiry in Unix nanoseconds to the Unix seconds
+// used by the API, keeping 0 for keys that do not expire.
+func unixSeconds(expireAt int64) int64 {
+	if expireAt == 0 {
+		return 0
+	}
+	return time.Unix(0, expireAt).Unix()
+}
+
+func (s *server) reaperLoop() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(reapInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			// Only the leader may write to the DB.
+			if !s.leader.writable() {
+				continue
+			}
+			if n := s.reapExpired(); n > 0 {
+				level.Debug(s.logger).Log("msg", "deleted expired keys", "count", n)
+			}
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// reapExpired deletes the keys that have expired and returns how many were
+// deleted. The deletes do not wait for the WAL: the keys are already hidden
+// from reads.
+func (s *server) reapExpired() int {
+	deleted := 0
+	for _, key := range s.index.expired(time.Now().UnixNano()) {
+		s.commitMu.RLock()
+		unlock := s.keyLocks.lock([]byte(key))
+
+		// The key may have been written again since it was listed.
+		if expired(s.index.expireAt(key), time.Now().UnixNano()) {
+			s.delete([]byte(key), slatedb.WriteOptions{AwaitFlush: false})
+			s.ops.expirations.Add(1)
+			deleted++
+		}
+
+		unlock()
+		s.commitMu.RUnlock()
+	}
+	return deleted
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestExpiryOf(t *testing.T) {
+	day := int64(24 * time.Hour / time.Second)
+	tests := []struct {
+		name                 string
+		ttlSeconds, expireAt int64
+		wantField            string // the field of the INVALID_ARGUMENT error, if any
+	}{
+		{name: "none"},
+		{name: "ttl", ttlSeconds: 60},
+		{name: "expire_at", expireAt: time.Now().Unix() + day},
+		{name: "latest expire_at", expireAt: maxExpireAt},
+		{name: "ttl of a century", ttlSeconds: 100 * 365 * day},
+		{name: "negative ttl", ttlSeconds: -1, wantField: "ttl_seconds"},
+		{name: "negative expire_at", expireAt: -1, wantField: "ttl_seconds"},
+		{name: "both", ttlSeconds: 1, expireAt: 1, wantField: "expire_at"},
+		{name: "ttl past 2262", ttlSeconds: 10000000000, wantField: "ttl_seconds"},
+		{name: "largest ttl", ttlSeconds: math.MaxInt64, wantField: "ttl_seconds"},
+		{name: "expire_at past 2262", expireAt: maxExpireAt + 1, wantField: "expire_at"},
+		{name: "largest expire_at", expireAt: math.MaxInt64, wantField: "expire_at"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := time.Now().UnixNano()
+			got, err := expiryOf(tt.ttlSeconds, tt.expireAt)
+			if tt.wantField != "" {
+				if status.Code(err) != codes.InvalidArgument || invalidField(err) != tt.wantField {
+					t.Fatalf("expiryOf(%d, %d) = %d, %v, want INVALID_ARGUMENT for %s",
+						tt.ttlSeconds, tt.expireAt, got, err, tt.wantField)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expiryOf(%d, %d) failed: %v", tt.ttlSeconds, tt.expireAt, err)
+			}
+
+			switch {
+			case tt.ttlSeconds > 0:
+				if earliest := before + tt.ttlSeconds*int64(time.Second); got < earliest || got > time.Now().UnixNano()+tt.ttlSeconds*int64(time.Second) {
+					t.Errorf("expiryOf(%d, 0) = %d, want %ds from now", tt.ttlSeconds, got, tt.ttlSeconds)
+				}
+			case tt.expireAt > 0:
+				if want := tt.expireAt * int64(time.Second); got != want || unixSeconds(got) != tt.expireAt {
+					t.Errorf("expiryOf(0, %d) = %d, want %d", tt.expireAt, got, want)
+				}
+			default:
+				if got != 0 {
+					t.Errorf("expiryOf(0, 0) = %d, want 0", got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	pbv1 "github.com/TFMV/slatedb_demo/proto"
+	pb "github.com/TFMV/slatedb_demo/proto/v2"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// The server implements the standard grpc.health.v1 service, for the
+// server as a whole ("") and for each of its APIs. They are SERVING while
+// the server serves requests, as /healthz reports, and NOT_SERVING once it
+// drains or is fenced. Health checks need no token, so that load balancers
+// and orchestrators can probe the server.
+
+// healthServices are the services whose status the health service reports.
+var healthServices = []string{
+	"",
+	pb.SlateDB_ServiceDesc.ServiceName,
+	pbv1.SlateDB_ServiceDesc.ServiceName,
+}
+
+// isHealthMethod reports whether fullMethod belongs to the health service.
+func isHealthMethod(fullMethod string) bool {
+	return strings.HasPrefix(fullMethod, "/"+healthpb.Health_ServiceDesc.ServiceName+"/")
+}
+
+// isReflectionMethod reports whether fullMethod belongs to the server
+// reflection service, in either version.
+func isReflectionMethod(fullMethod string) bool {
+	return strings.HasPrefix(fullMethod, "/grpc.reflection.")
+}
+
+// reportHealth sets the status of the services of hs to whether the server
+// serves requests, every fenceCheckInterval until done is closed.
+func reportHealth(logger log.Logger, hs *health.Server, leader *leadership, done <-chan struct{}) {
+	ticker := time.NewTicker(fenceCheckInterval)
+	defer ticker.Stop()
+
+	last := healthpb.HealthCheckResponse_SERVICE_UNKNOWN
+	for {
+		status := healthpb.HealthCheckResponse_NOT_SERVING
+		if leader.serving() {
+			status = healthpb.HealthCheckResponse_SERVING
+		}
+		if status != last {
+			for _, service := range healthServices {
+				hs.SetServingStatus(service, status)
+			}
+			level.Debug(logger).Log("msg", "health changed", "status", status)
+			last = status
+		}
+
+		select {
+		case <-ticker.C:
+		case <-done:
+			return
+		}
+	}
+}
+
+// endWatchesOnDrain ends the health Watch streams when the server drains,
+// after they sent NOT_SERVING, so that they do not hold up the graceful
+// stop.
+func (l *leadership) endWatchesOnDrain(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler) error {
+	if info.FullMethod != healthpb.Health_Watch_FullMethodName {
+		return handler(srv, ss)
+	}
+
+	ctx, cancel := context.WithCancel(ss.Context())
+	defer cancel()
+	go func() {
+		select {
+		case <-l.draining:
+			// Let the watch send NOT_SERVING first.
+			time.Sleep(100 * time.Millisecond)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return handler(srv, &drainingStream{ServerStream: ss, ctx: ctx})
+}
+
+// drainingStream is a stream whose context ends when the server drains.
+type drainingStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *drainingStream) Context() context.Context {
+	return s.ctx
+}
+
+// drain marks the server NOT_SERVING and keeps serving for delay, so that
+// load balancers stop sending it requests. It then stops accepting RPCs,
+// waits up to timeout for those in flight to finish and cancels those that
+// remain.
+func drain(logger log.Logger, grpcServer *grpc.Server, hs *health.Server, leader *leadership,
+	delay, timeout time.Duration) {
+	hs.Shutdown()
+	leader.drain()
+	if delay > 0 {
+		level.Info(logger).Log("msg", "reporting NOT_SERVING before stopping", "delay", delay)
+		time.Sleep(delay)
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(timeout):
+		level.Warn(logger).Log("msg", "RPCs still in flight after the drain timeout, cancelling them", "timeout", timeout)
+		grpcServer.Stop()
+		<-stopped
+	}
+}
@@ -0,0 +1,417 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"math"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/huandu/skiplist"
+	"github.com/thanos-io/objstore"
+)
+
+// keyIndex is an ordered, in-memory view of the keys stored in the DB.
+//
+// slatedb-go does not expose iterators yet, so scans and statistics are
+// served from this index. It is checkpointed to the bucket next to the DB,
+// and brought up to date from the journal when the DB is opened, so that it
+// survives restarts and crashes. Key expiry times are kept here as well.
+//
+// The index keeps running counts of the keys and bytes of every namespace,
+// and of the prefixes that statistics were asked for, so that GetStats does
+// not walk the keys. The counts include keys that have expired until the
+// reaper deletes them.
+type keyIndex struct {
+	mu       sync.RWMutex
+	keys     *skiplist.SkipList // ordered set of the keys
+	sizes    map[string]int64   // value size in bytes per key
+	expires  map[string]int64   // expiry in Unix nanoseconds, for keys that expire
+	total    keyCounts
+	spaces   map[string]*keyCounts    // per namespace name
+	prefixes map[string]*prefixCounts // per tracked prefix of stored keys
+	uses     uint64                   // prefix lookups, to order tracked prefixes by last use
+
+	// version counts the changes to the index, and saved is the version of
+	// the last checkpoint, or -1 if the index must be saved again.
+	version int64
+	saved   atomic.Int64
+}
+
+// keyCounts is a number of keys and their total size in bytes, keys
+// included.
+type keyCounts struct {
+	keys, bytes int64
+}
+
+// prefixCounts are the counts of a tracked prefix.
+type prefixCounts struct {
+	keyCounts
+	used uint64 // value of uses at the last lookup
+}
+
+// Each writer epoch saves its key index checkpoints under its own name in
+// indexDir, so that a checkpoint that a fenced writer was still uploading
+// cannot replace one of the new leader, which loads the checkpoint of the
+// latest epoch. Servers from before saved theirs as keys.gob.
+const (
+	indexDir          = "index"
+	legacyIndexObject = "keys.gob"
+)
+
+// indexCheckpointName returns the name of the key index checkpoint of the
+// writer epoch, for the DB at dbPath.
+func indexCheckpointName(dbPath string, epoch uint64) string {
+	return path.Join(dbPath, indexDir, fmt.Sprintf("keys-%020d.gob", epoch))
+}
+
+// listIndexCheckpoints returns the names of the key index checkpoints of
+// the DB at dbPath, oldest first.
+func listIndexCheckpoints(ctx context.Context, bucket objstore.Bucket, dbPath string) ([]string, error) {
+	var legacy bool
+	var names []string
+	err := bucket.Iter(ctx, path.Join(dbPath, indexDir)+"/", func(name string) error {
+		switch base := path.Base(name); {
+		case base == legacyIndexObject:
+			legacy = true
+		case strings.HasPrefix(base, "keys-") && path.Ext(base) == ".gob":
+			names = append(names, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list index checkpoints: %v", err)
+	}
+	// Epochs are zero-padded, so the names sort in epoch order.
+	slices.Sort(names)
+	if legacy {
+		names = append([]string{path.Join(dbPath, indexDir, legacyIndexObject)}, names...)
+	}
+	return names, nil
+}
+
+// maxTrackedPrefixes bounds the number of prefixes the index keeps counts
+// for. The least recently used prefix is dropped to track a new one.
+const maxTrackedPrefixes = 256
+
+// indexCheckpoint is the on-bucket representation of a keyIndex. Journal is
+// the position of the first journal entry that the checkpoint misses.
+type indexCheckpoint struct {
+	Sizes   map[string]int64
+	Expires map[string]int64
+	Journal journalPosition
+}
+
+func newKeyIndex() *keyIndex {
+	return &keyIndex{
+		keys:     skiplist.New(skiplist.String),
+		sizes:    make(map[string]int64),
+		expires:  make(map[string]int64),
+		spaces:   make(map[string]*keyCounts),
+		prefixes: make(map[string]*prefixCounts),
+	}
+}
+
+// count adds keys and bytes to the counts of key: the total, those of its
+// namespace and those of the tracked prefixes of key. The caller holds mu.
+func (ix *keyIndex) count(key string, keys, bytes int64) {
+	ix.total.keys += keys
+	ix.total.bytes += bytes
+
+	name := namespaceOf(key)
+	space := ix.spaces[name]
+	if space == nil {
+		space = &keyCounts{}
+		ix.spaces[name] = space
+	}
+	space.keys += keys
+	space.bytes += bytes
+	if space.keys == 0 {
+		delete(ix.spaces, name)
+	}
+
+	for prefix, counts := range ix.prefixes {
+		if strings.HasPrefix(key, prefix) {
+			counts.keys += keys
+			counts.bytes += bytes
+		}
+	}
+}
+
+// put records key with a value of the given size, expiring at expireAt in
+// Unix nanoseconds. An expireAt of 0 means the key does not expire.
+func (ix *keyIndex) put(key string, size int, expireAt int64) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	if old, ok := ix.sizes[key]; ok {
+		ix.count(key, 0, int64(size)-old)
+	} else {
+		ix.keys.Set(key, nil)
+		ix.count(key, 1, int64(len(key))+int64(size))
+	}
+	ix.sizes[key] = int64(size)
+	if expireAt > 0 {
+		ix.expires[key] = expireAt
+	} else {
+		delete(ix.expires, key)
+	}
+	ix.version++
+}
+
+// delete removes key from the index. It is a no-op for unknown keys.
+func (ix *keyIndex) delete(key string) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	old, ok := ix.sizes[key]
+	if !ok {
+		return
+	}
+	ix.keys.Remove(key)
+	delete(ix.sizes, key)
+	delete(ix.expires, key)
+	ix.count(key, -1, -int64(len(key))-old)
+	ix.version++
+}
+
+// expireAt returns the expiry of key in Unix nanoseconds, or 0 if it does
+// not expire.
+func (ix *keyIndex) expireAt(key string) int64 {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return ix.expires[key]
+}
+
+// expired returns the keys that have expired at now, in Unix nanoseconds.
+func (ix *keyIndex) expired(now int64) []string {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	var keys []string
+	for key, expireAt := range ix.expires {
+		if expireAt <= now {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// stats returns the number of keys and their total size in bytes.
+func (ix *keyIndex) stats() (int64, int64) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return ix.total.keys, ix.total.bytes
+}
+
+// namespaceStats returns the number of stored keys of the namespace name and
+// their total size in bytes.
+func (ix *keyIndex) namespaceStats(name string) (int64, int64) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	if space := ix.spaces[name]; space != nil {
+		return space.keys, space.bytes
+	}
+	return 0, 0
+}
+
+// prefixStats returns the number of stored keys with prefix and their total
+// size in bytes. The first lookup of a prefix counts its keys, and the
+// prefix is then tracked by put and delete.
+func (ix *keyIndex) prefixStats(prefix string) (int64, int64) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	ix.uses++
+	if counts, ok := ix.prefixes[prefix]; ok {
+		counts.used = ix.uses
+		return counts.keys, counts.bytes
+	}
+
+	if len(ix.prefixes) == maxTrackedPrefixes {
+		lru, oldest := "", uint64(math.MaxUint64)
+		for p, counts := range ix.prefixes {
+			if counts.used < oldest {
+				lru, oldest = p, counts.used
+			}
+		}
+		delete(ix.prefixes, lru)
+	}
+
+	counts := &prefixCounts{used: ix.uses}
+	end := prefixEnd(prefix)
+	for elem := ix.keys.Find(prefix); elem != nil; elem = elem.Next() {
+		key := elem.Key().(string)
+		if end != "" && key >= end {
+			break
+		}
+		counts.keys++
+		counts.bytes += int64(len(key)) + ix.sizes[key]
+	}
+	ix.prefixes[prefix] = counts
+	return counts.keys, counts.bytes
+}
+
+// rangeStats returns the number of unexpired keys in [start, end) and their
+// total size in bytes. An empty end means no upper bound.
+func (ix *keyIndex) rangeStats(start, end string) (int64, int64) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	now := time.Now().UnixNano()
+
+	var count, size int64
+	for elem := ix.keys.Find(start); elem != nil; elem = elem.Next() {
+		key := elem.Key().(string)
+		if end != "" && key >= end {
+			break
+		}
+		if expireAt, ok := ix.expires[key]; ok && expireAt <= now {
+			continue
+		}
+		count++
+		size += int64(len(key)) + ix.sizes[key]
+	}
+	return count, size
+}
+
+// scan returns up to limit unexpired keys in [start, end) in ascending
+// order. An empty end means no upper bound and a limit <= 0 means no limit.
+func (ix *keyIndex) scan(start, end string, limit int) []string {
+	return ix.scanAt(start, end, limit, time.Now().UnixNano())
+}
+
+// scanAt is scan for keys that had not expired at now, in Unix nanoseconds.
+func (ix *keyIndex) scanAt(start, end string, limit int, now int64) []string {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	var keys []string
+	for elem := ix.keys.Find(start); elem != nil; elem = elem.Next() {
+		key := elem.Key().(string)
+		if end != "" && key >= end {
+			break
+		}
+		if limit > 0 && len(keys) == limit {
+			break
+		}
+		if expireAt, ok := ix.expires[key]; ok && expireAt <= now {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// load replaces the contents of the index with the checkpoint stored under
+// name, and returns the journal position the checkpoint is up to date
+// with. A missing checkpoint leaves the index empty.
+func (ix *keyIndex) load(ctx context.Context, bucket objstore.Bucket, name string) (journalPosition, error) {
+	rc, err := bucket.Get(ctx, name)
+	if err != nil {
+		if bucket.IsObjNotFoundErr(err) {
+			return journalPosition{}, nil
+		}
+		return journalPosition{}, fmt.Errorf("failed to read index checkpoint: %v", err)
+	}
+	defer rc.Close()
+
+	var cp indexCheckpoint
+	if err := gob.NewDecoder(rc).Decode(&cp); err != nil {
+		return journalPosition{}, fmt.Errorf("failed to decode index checkpoint: %v", err)
+	}
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	ix.keys = skiplist.New(skiplist.String)
+	ix.sizes = make(map[string]int64, len(cp.Sizes))
+	ix.expires = make(map[string]int64, len(cp.Expires))
+	ix.total = keyCounts{}
+	ix.spaces = make(map[string]*keyCounts)
+	ix.prefixes = make(map[string]*prefixCounts)
+	for key, size := range cp.Sizes {
+		ix.keys.Set(key, nil)
+		ix.sizes[key] = size
+		ix.count(key, 1, int64(len(key))+size)
+	}
+	for key, expireAt := range cp.Expires {
+		ix.expires[key] = expireAt
+	}
+	ix.saved.Store(ix.version)
+	return cp.Journal, nil
+}
+
+// replace replaces the contents of the index with those of other.
+func (ix *keyIndex) replace(other *keyIndex) {
+	other.mu.RLock()
+	defer other.mu.RUnlock()
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	ix.keys, ix.sizes, ix.expires = other.keys, other.sizes, other.expires
+	ix.total, ix.spaces, ix.prefixes = other.total, other.spaces, other.prefixes
+	ix.saved.Store(ix.version)
+}
+
+// checkpoint returns a copy of the index to save, if it changed since the
+// last load or checkpoint. The copy is taken under the read lock, so that
+// scans go on meanwhile.
+func (ix *keyIndex) checkpoint() (*indexCheckpoint, bool) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	if ix.version == ix.saved.Load() {
+		return nil, false
+	}
+	cp := &indexCheckpoint{
+		Sizes:   make(map[string]int64, len(ix.sizes)),
+		Expires: make(map[string]int64, len(ix.expires)),
+	}
+	for key, size := range ix.sizes {
+		cp.Sizes[key] = size
+	}
+	for key, expireAt := range ix.expires {
+		cp.Expires[key] = expireAt
+	}
+	ix.saved.Store(ix.version)
+	return cp, true
+}
+
+// save writes cp under name. If it fails, the index is saved again by the
+// next checkpoint.
+func (ix *keyIndex) save(ctx context.Context, bucket objstore.Bucket, name string, cp *indexCheckpoint) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(cp); err != nil {
+		return fmt.Errorf("failed to encode index checkpoint: %v", err)
+	}
+	if err := bucket.Upload(ctx, name, &buf); err != nil {
+		ix.markDirty()
+		return fmt.Errorf("failed to write index checkpoint: %v", err)
+	}
+	return nil
+}
+
+// markDirty makes the next checkpoint save the index again.
+func (ix *keyIndex) markDirty() {
+	ix.saved.Store(-1)
+}
+
+// prefixEnd returns the smallest key that is greater than every key with
+// the given prefix, or "" if there is no such key.
+func prefixEnd(prefix string) string {
+	end := []byte(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return string(end[:i+1])
+		}
+	}
+	return ""
+}
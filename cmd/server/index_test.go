@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/thanos-io/objstore"
+)
+
+// wantCounts checks a number of keys and bytes returned by the index.
+func wantCounts(t *testing.T, what string, keys, bytes, wantKeys, wantBytes int64) {
+	t.Helper()
+	if keys != wantKeys || bytes != wantBytes {
+		t.Errorf("%s = %d keys, %d bytes, want %d keys, %d bytes", what, keys, bytes, wantKeys, wantBytes)
+	}
+}
+
+func TestKeyIndexCounts(t *testing.T) {
+	ix := newKeyIndex()
+	a := newNamespace("a")
+
+	ix.put("user:1", 10, 0)
+	ix.put("user:2", 20, 0)
+	ix.put("order:1", 5, 0)
+	ix.put(string(a.key([]byte("user:1"))), 7, 0)
+
+	// Tracking a prefix counts the keys it already has.
+	keys, bytes := ix.prefixStats("user:")
+	wantCounts(t, "prefix user:", keys, bytes, 2, 6+10+6+20)
+
+	ix.put("user:1", 15, 0) // overwrite
+	ix.put("user:3", 1, 0)
+	ix.delete("user:2")
+	ix.delete("user:9") // unknown
+	ix.put(string(a.key([]byte("user:2"))), 3, 0)
+
+	keys, bytes = ix.stats()
+	wantCounts(t, "total", keys, bytes, 5, 6+15+6+1+7+5+9+7+9+3)
+	keys, bytes = ix.namespaceStats("")
+	wantCounts(t, "default namespace", keys, bytes, 3, 6+15+6+1+7+5)
+	keys, bytes = ix.namespaceStats("a")
+	wantCounts(t, "namespace a", keys, bytes, 2, 9+7+9+3)
+	keys, bytes = ix.namespaceStats("b")
+	wantCounts(t, "namespace b", keys, bytes, 0, 0)
+	keys, bytes = ix.prefixStats("user:")
+	wantCounts(t, "prefix user:", keys, bytes, 2, 6+15+6+1)
+	keys, bytes = ix.prefixStats(string(a.key([]byte("user:"))))
+	wantCounts(t, "prefix user: of namespace a", keys, bytes, 2, 9+7+9+3)
+
+	// The counts survive a checkpoint.
+	ctx := context.Background()
+	bucket := objstore.NewInMemBucket()
+	cp, _ := ix.checkpoint()
+	if err := ix.save(ctx, bucket, indexCheckpointName(testDBPath, 1), cp); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	loaded := newKeyIndex()
+	if _, err := loaded.load(ctx, bucket, indexCheckpointName(testDBPath, 1)); err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	keys, bytes = loaded.stats()
+	wantCounts(t, "loaded total", keys, bytes, 5, 6+15+6+1+7+5+9+7+9+3)
+	keys, bytes = loaded.namespaceStats("a")
+	wantCounts(t, "loaded namespace a", keys, bytes, 2, 9+7+9+3)
+	keys, bytes = loaded.prefixStats("user:")
+	wantCounts(t, "loaded prefix user:", keys, bytes, 2, 6+15+6+1)
+}
+
+func TestKeyIndexTrackedPrefixes(t *testing.T) {
+	ix := newKeyIndex()
+	for i := 0; i < maxTrackedPrefixes; i++ {
+		ix.prefixStats(fmt.Sprintf("p%03d:", i))
+	}
+	ix.prefixStats("p000:") // now the most recently used
+
+	// Tracking one more prefix drops the least recently used one.
+	ix.prefixStats("new:")
+	if len(ix.prefixes) != maxTrackedPrefixes {
+		t.Fatalf("tracking %d prefixes, want %d", len(ix.prefixes), maxTrackedPrefixes)
+	}
+	if _, ok := ix.prefixes["p001:"]; ok {
+		t.Error("the least recently used prefix p001: is still tracked")
+	}
+	if _, ok := ix.prefixes["p000:"]; !ok {
+		t.Error("the recently used prefix p000: was dropped")
+	}
+
+	// A prefix tracked again is counted again.
+	ix.put("p001:k", 4, 0)
+	keys, bytes := ix.prefixStats("p001:")
+	wantCounts(t, "prefix p001:", keys, bytes, 1, 6+4)
+}
+
+func TestNamespaceOf(t *testing.T) {
+	tests := []struct {
+		key, want string
+	}{
+		{"user:1", ""},
+		{"\x01", ""},
+		{"\x00a\x00k", "a"},
+		{"\x00tenant\x00", "tenant"},
+	}
+	for _, tt := range tests {
+		if got := namespaceOf(tt.key); got != tt.want {
+			t.Errorf("namespaceOf(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+		if !newNamespace(tt.want).contains([]byte(tt.key)) {
+			t.Errorf("namespace %q does not contain %q", tt.want, tt.key)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+
+	"github.com/go-kit/log/level"
+	"github.com/slatedb/slatedb-go/slatedb"
+	"github.com/slatedb/slatedb-go/slatedb/common"
+)
+
+// slatedb-go has no write batches, so Write first stores all of its
+// operations in a single intent record and waits until it is durable, then
+// applies them and deletes the record. WAL writes are durable in the order
+// they were made: after a crash either the record is missing and so are the
+// operations, or it is still there and the operations are applied again
+// when the DB is opened.
+
+// writeIntentKey holds the intent record of the Write being applied.
+const writeIntentKey = "\x00\x01w"
+
+// writeIntent is the intent record of a Write.
+type writeIntent struct {
+	Ops []intentOp
+}
+
+// intentOp is a put, or a delete if Delete is set, of a key of the DB.
+type intentOp struct {
+	Key      []byte
+	Value    []byte
+	ExpireAt int64
+	Delete   bool
+}
+
+// store writes the record and waits until it is durable.
+func (w *writeIntent) store(db *slatedb.DB) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(w); err != nil {
+		return fmt.Errorf("failed to encode write intent: %v", err)
+	}
+	db.PutWithOptions([]byte(writeIntentKey), buf.Bytes(), slatedb.WriteOptions{AwaitFlush: true})
+	return nil
+}
+
+// clearWriteIntent deletes the intent record once its operations were
+// applied, and waits until they are durable.
+func clearWriteIntent(db *slatedb.DB) {
+	db.DeleteWithOptions([]byte(writeIntentKey), slatedb.WriteOptions{AwaitFlush: true})
+}
+
+// readWriteIntent returns the intent record left in db, if any.
+func readWriteIntent(db *slatedb.DB) (*writeIntent, bool, error) {
+	data, err := db.Get([]byte(writeIntentKey))
+	if errors.Is(err, common.ErrKeyNotFound) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read write intent: %v", err)
+	}
+	var w writeIntent
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&w); err != nil {
+		return nil, false, fmt.Errorf("failed to decode write intent: %v", err)
+	}
+	return &w, true, nil
+}
+
+// apply applies the operations of w in order. The caller holds commitMu
+// exclusively.
+func (s *server) apply(w *writeIntent) {
+	noFlush := slatedb.WriteOptions{AwaitFlush: false}
+	for _, op := range w.Ops {
+		if op.Delete {
+			s.delete(op.Key, noFlush)
+		} else {
+			s.put(op.Key, op.Value, op.ExpireAt, noFlush)
+		}
+	}
+}
+
+// applyTo applies the operations of w to db and index only. A replica
+// does so to see a Write of the primary in full, as its own writes stay in
+// memory.
+func (w *writeIntent) applyTo(db *slatedb.DB, index *keyIndex) {
+	noFlush := slatedb.WriteOptions{AwaitFlush: false}
+	for _, op := range w.Ops {
+		if op.Delete {
+			db.DeleteWithOptions(op.Key, noFlush)
+			index.delete(string(op.Key))
+			continue
+		}
+		db.PutWithOptions(op.Key, encodeValue(op.Value, op.ExpireAt), noFlush)
+		index.put(string(op.Key), len(op.Value), op.ExpireAt)
+	}
+}
+
+// redoWrite applies again the Write that a crash interrupted, if any.
+func (s *server) redoWrite() error {
+	w, found, err := readWriteIntent(s.db)
+	if err != nil || !found {
+		return err
+	}
+
+	s.commitMu.Lock()
+	defer s.commitMu.Unlock()
+	s.apply(w)
+	clearWriteIntent(s.db)
+	level.Info(s.logger).Log("msg", "applied an interrupted write again", "operations", len(w.Ops))
+	return nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	pb "github.com/TFMV/slatedb_demo/proto/v2"
+	"github.com/thanos-io/objstore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRedoWrite(t *testing.T) {
+	bucket := objstore.NewInMemBucket()
+	s := openTestServer(t, bucket)
+	mustPut(t, s, "gone", "x")
+
+	expireAt := time.Now().Add(time.Hour).Truncate(time.Second).UnixNano()
+	intent := &writeIntent{Ops: []intentOp{
+		{Key: []byte("a"), Value: []byte("1")},
+		{Key: []byte("b"), Value: []byte("2"), ExpireAt: expireAt},
+		{Key: []byte("gone"), Delete: true},
+		{Key: []byte("a"), Value: []byte("3")}, // operations apply in order
+		{Key: newNamespace("ns").key([]byte("c")), Value: []byte("4")},
+	}}
+
+	// Crash once the intent record is durable, before any operation of the
+	// Write was applied.
+	if err := intent.store(s.db); err != nil {
+		t.Fatalf("failed to store the write intent: %v", err)
+	}
+	crash(t, s)
+
+	s = openTestServer(t, bucket)
+	defer s.Close()
+
+	wantValue(t, s, "a", []byte("3"))
+	wantValue(t, s, "b", []byte("2"))
+	wantValue(t, s, "gone", nil)
+	if got := s.index.expireAt("b"); got != expireAt {
+		t.Errorf("expiry of b = %d, want %d", got, expireAt)
+	}
+	if got, want := s.index.scan("", "", 0), []string{"\x00ns\x00c", "a", "b"}; !slices.Equal(got, want) {
+		t.Errorf("index holds %q, want %q", got, want)
+	}
+	if _, found, err := readWriteIntent(s.db); found || err != nil {
+		t.Errorf("write intent after reopening: found %v, err %v; want it deleted", found, err)
+	}
+}
+
+func TestWriteLeavesNoIntent(t *testing.T) {
+	s := openTestServer(t, objstore.NewInMemBucket())
+	defer s.Close()
+	mustPut(t, s, "b", "old")
+
+	_, err := s.Write(context.Background(), &pb.WriteRequest{Operations: []*pb.WriteOperation{
+		putOp("a", "1"), deleteOp("b"), putOp("c", ""),
+	}})
+	if err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	wantValue(t, s, "a", []byte("1"))
+	wantValue(t, s, "b", nil)
+	wantValue(t, s, "c", []byte(""))
+	if _, found, err := readWriteIntent(s.db); found || err != nil {
+		t.Errorf("write intent after Write: found %v, err %v; want it deleted", found, err)
+	}
+}
+
+func TestWriteRejectedChangesNothing(t *testing.T) {
+	s := openTestServer(t, objstore.NewInMemBucket())
+	defer s.Close()
+	mustPut(t, s, "x", "old")
+	mustPut(t, s, "y", "old")
+
+	withTTL := func(op *pb.WriteOperation, ttlSeconds int64) *pb.WriteOperation {
+		op.GetPut().TtlSeconds = ttlSeconds
+		return op
+	}
+	tests := []struct {
+		name      string
+		namespace string
+		bad       *pb.WriteOperation
+		code      codes.Code
+	}{
+		{name: "empty put key", bad: putOp("", "v"), code: codes.InvalidArgument},
+		{name: "empty delete key", bad: deleteOp(""), code: codes.InvalidArgument},
+		{name: "reserved key", bad: putOp("\x00\x01w", "v"), code: codes.InvalidArgument},
+		{name: "no operation", bad: &pb.WriteOperation{}, code: codes.InvalidArgument},
+		{name: "negative ttl", bad: withTTL(putOp("z", "v"), -1), code: codes.InvalidArgument},
+		{name: "ttl past 2262", bad: withTTL(putOp("z", "v"), 10000000000), code: codes.InvalidArgument},
+		{name: "unknown namespace", namespace: "missing", bad: putOp("z", "v"), code: codes.NotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.Write(context.Background(), &pb.WriteRequest{
+				Namespace:  tt.namespace,
+				Operations: []*pb.WriteOperation{putOp("x", "new"), deleteOp("y"), putOp("z", "new"), tt.bad},
+			})
+			if status.Code(err) != tt.code {
+				t.Fatalf("Write = %v, want %s", err, tt.code)
+			}
+			wantValue(t, s, "x", []byte("old"))
+			wantValue(t, s, "y", []byte("old"))
+			wantValue(t, s, "z", nil)
+			if _, found, _ := readWriteIntent(s.db); found {
+				t.Error("the rejected Write left a write intent")
+			}
+		})
+	}
+}
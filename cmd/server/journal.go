@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/slatedb/slatedb-go/slatedb"
+	"github.com/slatedb/slatedb-go/slatedb/common"
+)
+
+// The key index is checkpointed every checkpointInterval, so after a crash
+// the checkpoint misses the keys written since. Every write therefore first
+// records its key in a journal kept in the DB. WAL writes are durable in the
+// order they were made, so a write that survived a crash has its journal
+// entry too. When the DB is opened, the keys journaled after the checkpoint
+// are read from the DB again to bring the index up to date, and once a
+// checkpoint is saved the entries it covers are deleted.
+//
+// Journal entries are stored under 0x00 0x01 'j' followed by the writer
+// epoch and a sequence number, both big-endian, so that each writer of the
+// DB has its own entries. Namespace names cannot start with 0x01, so no
+// namespace holds these keys.
+
+const (
+	journalPrefix = "\x00\x01j"
+
+	// journalSyncKey holds the journal position of the latest checkpoint.
+	journalSyncKey = "\x00\x01s"
+
+	// syncPollInterval is how often sync checks whether its write is durable.
+	syncPollInterval = 10 * time.Millisecond
+)
+
+// journalPosition is the position in the journal of the next entry, that a
+// checkpoint of the index is up to date with.
+type journalPosition struct {
+	Epoch uint64
+	Seq   uint64
+}
+
+// journalRange is the entries [start, end) of one epoch.
+type journalRange struct {
+	epoch, start, end uint64
+}
+
+func journalKey(epoch, seq uint64) []byte {
+	key := make([]byte, 0, len(journalPrefix)+16)
+	key = append(key, journalPrefix...)
+	key = binary.BigEndian.AppendUint64(key, epoch)
+	return binary.BigEndian.AppendUint64(key, seq)
+}
+
+// journal appends the keys written to the DB to the journal.
+type journal struct {
+	mu    sync.Mutex
+	epoch uint64
+	next  uint64 // sequence of the next entry
+
+	// pending holds the entries whose write may not be in the index yet.
+	pending map[uint64]struct{}
+	syncs   uint64 // number of calls to sync, to tell their writes apart
+
+	// trimmed is the first entry of epoch that was not deleted, and
+	// replayed the entries of earlier epochs read when the DB was opened.
+	trimmed  uint64
+	replayed []journalRange
+}
+
+func newJournal(epoch uint64) *journal {
+	return &journal{epoch: epoch, pending: make(map[uint64]struct{})}
+}
+
+// record appends key to the journal and returns the sequence of its entry,
+// to pass to done once the write of key is in the index. It does not wait
+// for the WAL flush: the write of key that follows does.
+func (j *journal) record(db *slatedb.DB, key []byte) uint64 {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	// Entries are written while holding mu, so that the WAL holds them in
+	// the order of their sequence numbers and never skips one.
+	seq := j.next
+	db.PutWithOptions(journalKey(j.epoch, seq), key, slatedb.WriteOptions{AwaitFlush: false})
+	j.pending[seq] = struct{}{}
+	j.next++
+	return seq
+}
+
+// done marks the write of the entry seq as applied to the index.
+func (j *journal) done(seq uint64) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	delete(j.pending, seq)
+}
+
+// position returns the position of the first entry whose write may not be
+// in the index yet. A copy of the index taken after it was called holds the
+// writes of every entry before it.
+func (j *journal) position() journalPosition {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	pos := journalPosition{Epoch: j.epoch, Seq: j.next}
+	for seq := range j.pending {
+		pos.Seq = min(pos.Seq, seq)
+	}
+	return pos
+}
+
+// sync records pos in the DB and waits until that write, and so every
+// write made before it, is durable. slatedb-go has no call to flush the WAL,
+// and it drops some of the notifications when several writes wait for the
+// same flush: rather than wait for one alongside the writes of RPCs, sync
+// polls until a committed read sees its write.
+func (j *journal) sync(ctx context.Context, db *slatedb.DB, pos journalPosition) error {
+	j.mu.Lock()
+	j.syncs++
+	value := binary.BigEndian.AppendUint64(nil, pos.Epoch)
+	value = binary.BigEndian.AppendUint64(value, pos.Seq)
+	value = binary.BigEndian.AppendUint64(value, j.syncs)
+	db.PutWithOptions([]byte(journalSyncKey), value, slatedb.WriteOptions{AwaitFlush: false})
+	j.mu.Unlock()
+
+	ticker := time.NewTicker(syncPollInterval)
+	defer ticker.Stop()
+	for {
+		current, err := db.Get([]byte(journalSyncKey))
+		if err != nil && !errors.Is(err, common.ErrKeyNotFound) {
+			return fmt.Errorf("failed to read journal position: %v", err)
+		}
+		if bytes.Equal(current, value) {
+			return nil
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// trim deletes the entries before pos, which a saved checkpoint covers.
+func (j *journal) trim(db *slatedb.DB, pos journalPosition) {
+	j.mu.Lock()
+	ranges := append(j.replayed, journalRange{epoch: j.epoch, start: j.trimmed, end: pos.Seq})
+	j.replayed, j.trimmed = nil, pos.Seq
+	j.mu.Unlock()
+
+	for _, r := range ranges {
+		for seq := r.start; seq < r.end; seq++ {
+			db.DeleteWithOptions(journalKey(r.epoch, seq), slatedb.WriteOptions{AwaitFlush: false})
+		}
+	}
+}
+
+// replayJournal reads the keys journaled in db from pos through the entries
+// of epoch last, and updates the index with their state in db. It returns
+// the entries it read.
+func replayJournal(db *slatedb.DB, index *keyIndex, pos journalPosition, last uint64) ([]journalRange, error) {
+	var replayed []journalRange
+	for epoch := pos.Epoch; epoch <= last; epoch++ {
+		r := journalRange{epoch: epoch}
+		if epoch == pos.Epoch {
+			r.start = pos.Seq
+		}
+		for r.end = r.start; ; r.end++ {
+			key, err := db.Get(journalKey(epoch, r.end))
+			if errors.Is(err, common.ErrKeyNotFound) {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to read journal: %v", err)
+			}
+			if err := reindex(db, index, key); err != nil {
+				return nil, err
+			}
+		}
+		if r.end > r.start {
+			replayed = append(replayed, r)
+		}
+	}
+	return replayed, nil
+}
+
+// reindex updates the entry of key in the index to its state in db.
+func reindex(db *slatedb.DB, index *keyIndex, key []byte) error {
+	value, expireAt, err := readValue(db, key, slatedb.DefaultReadOptions())
+	if errors.Is(err, common.ErrKeyNotFound) {
+		index.delete(string(key))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read key '%s': %v", displayKey(key), err)
+	}
+	index.put(string(key), len(value), expireAt)
+	return nil
+}
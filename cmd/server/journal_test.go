@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	pb "github.com/TFMV/slatedb_demo/proto/v2"
+	"github.com/slatedb/slatedb-go/slatedb"
+	"github.com/thanos-io/objstore"
+)
+
+// indexState is what the key index of a server holds.
+type indexState struct {
+	keys            []string
+	expires         map[string]int64
+	keyCount, bytes int64
+	nsKeys, nsBytes int64 // of namespace ns
+}
+
+func stateOf(s *server) indexState {
+	st := indexState{keys: s.index.scan("", "", 0), expires: make(map[string]int64)}
+	for _, key := range st.keys {
+		if expireAt := s.index.expireAt(key); expireAt > 0 {
+			st.expires[key] = expireAt
+		}
+	}
+	st.keyCount, st.bytes = s.index.stats()
+	st.nsKeys, st.nsBytes = s.index.namespaceStats("ns")
+	return st
+}
+
+func wantState(t *testing.T, s *server, want indexState) {
+	t.Helper()
+	got := stateOf(s)
+	if !slices.Equal(got.keys, want.keys) {
+		t.Errorf("index holds %q, want %q", got.keys, want.keys)
+	}
+	for key, expireAt := range want.expires {
+		if got.expires[key] != expireAt {
+			t.Errorf("expiry of %q = %d, want %d", key, got.expires[key], expireAt)
+		}
+	}
+	if len(got.expires) != len(want.expires) {
+		t.Errorf("index holds %d expiries, want %d", len(got.expires), len(want.expires))
+	}
+	wantCounts(t, "total", got.keyCount, got.bytes, want.keyCount, want.bytes)
+	wantCounts(t, "namespace ns", got.nsKeys, got.nsBytes, want.nsKeys, want.nsBytes)
+}
+
+// replayedEntries returns the number of journal entries s read when it
+// opened the DB.
+func replayedEntries(s *server) uint64 {
+	var n uint64
+	for _, r := range s.journal.replayed {
+		n += r.end - r.start
+	}
+	return n
+}
+
+func TestJournalReplay(t *testing.T) {
+	ctx := context.Background()
+	bucket := objstore.NewInMemBucket()
+	s := openTestServer(t, bucket)
+
+	if _, err := s.CreateNamespace(ctx, &pb.CreateNamespaceRequest{Name: "ns"}); err != nil {
+		t.Fatalf("CreateNamespace failed: %v", err)
+	}
+	mustPut(t, s, "a", "1")
+	mustPut(t, s, "b", "22")
+	mustPut(t, s, "a", "333") // overwrite
+	mustPut(t, s, "gone", "x")
+	if _, err := s.Delete(ctx, &pb.DeleteRequest{Key: []byte("gone")}); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := s.Put(ctx, &pb.PutRequest{Key: []byte("t"), Value: []byte("v"), TtlSeconds: 3600}); err != nil {
+		t.Fatalf("Put with a TTL failed: %v", err)
+	}
+	if _, err := s.Put(ctx, &pb.PutRequest{Key: []byte("c"), Value: []byte("4444"), Namespace: "ns"}); err != nil {
+		t.Fatalf("Put in namespace ns failed: %v", err)
+	}
+
+	want := stateOf(s)
+	if want.nsKeys != 1 || want.nsBytes != int64(len("\x00ns\x00c")+4) {
+		t.Fatalf("namespace ns holds %d keys, %d bytes before the crash", want.nsKeys, want.nsBytes)
+	}
+
+	// Without a checkpoint, the index is rebuilt from the journal alone.
+	crash(t, s)
+	s = openTestServer(t, bucket)
+	defer s.Close()
+
+	wantState(t, s, want)
+	wantValue(t, s, "a", []byte("333"))
+	wantValue(t, s, "gone", nil)
+	if replayedEntries(s) == 0 {
+		t.Error("no journal entries were replayed")
+	}
+}
+
+func TestJournalTrim(t *testing.T) {
+	ctx := context.Background()
+	bucket := objstore.NewInMemBucket()
+	s := openTestServer(t, bucket)
+
+	mustPut(t, s, "a", "1")
+	mustPut(t, s, "b", "2")
+	if err := s.checkpoint(ctx); err != nil {
+		t.Fatalf("checkpoint failed: %v", err)
+	}
+	pos := s.journal.position()
+	for seq := uint64(0); seq < pos.Seq; seq++ {
+		_, err := s.db.GetWithOptions(journalKey(pos.Epoch, seq), slatedb.ReadOptions{ReadLevel: slatedb.Uncommitted})
+		if err == nil {
+			t.Errorf("journal entry %d is left after the checkpoint at %d", seq, pos.Seq)
+		}
+	}
+
+	mustPut(t, s, "c", "3")
+	mustPut(t, s, "a", "4")
+	want := stateOf(s)
+
+	// Only the entries after the checkpoint are replayed.
+	crash(t, s)
+	s = openTestServer(t, bucket)
+	wantState(t, s, want)
+	if n := replayedEntries(s); n != 2 {
+		t.Errorf("replayed %d journal entries, want 2", n)
+	}
+
+	// Close saves a checkpoint, so nothing is left to replay.
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	s = openTestServer(t, bucket)
+	defer s.Close()
+	wantState(t, s, want)
+	if n := replayedEntries(s); n != 0 {
+		t.Errorf("replayed %d journal entries after Close, want 0", n)
+	}
+}
+
+func TestCheckpointDuringWrites(t *testing.T) {
+	ctx := context.Background()
+	bucket := objstore.NewInMemBucket()
+	s := openTestServer(t, bucket)
+
+	// Writes go on while checkpoints are taken; whatever a checkpoint
+	// missed, the journal holds.
+	const n = 30
+	written := make(chan struct{})
+	go func() {
+		defer close(written)
+		for i := 0; i < n; i++ {
+			key := fmt.Sprintf("k%03d", i)
+			if _, err := s.Put(ctx, &pb.PutRequest{Key: []byte(key), Value: []byte("v")}); err != nil {
+				t.Errorf("Put(%q) failed: %v", key, err)
+			}
+		}
+	}()
+	stop := make(chan struct{})
+	checkpoints := make(chan error, 1)
+	go func() {
+		var err error
+		for {
+			select {
+			case <-stop:
+				checkpoints <- err
+				return
+			case <-time.After(time.Millisecond):
+			}
+			if cerr := s.checkpoint(ctx); cerr != nil {
+				err = cerr
+			}
+		}
+	}()
+	<-written
+	close(stop)
+	if err := <-checkpoints; err != nil {
+		t.Fatalf("checkpoint failed: %v", err)
+	}
+	want := stateOf(s)
+	if len(want.keys) != n {
+		t.Fatalf("index holds %d keys, want %d", len(want.keys), n)
+	}
+
+	crash(t, s)
+	s = openTestServer(t, bucket)
+	defer s.Close()
+	wantState(t, s, want)
+}
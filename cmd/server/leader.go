@@ -0,0 +1,462 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/TFMV/slatedb_demo/proto/v2"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/thanos-io/objstore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// Several servers may be started on one database, but only one of them, the
+// leader, may write to it. slatedb-go fences writers with an epoch: opening
+// the DB increments the writer epoch of the manifest, and a writer whose
+// epoch is no longer the latest cannot update the manifest. slatedb-go 0.1.3
+// only logs this, though, and keeps flushing its WAL over that of the new
+// writer. The leader therefore reads the writer epoch of the latest manifest
+// every fenceCheckInterval and stops serving once it is fenced, without
+// flushing anything; writes it accepted in between may be lost. It also
+// checks the epoch before it saves the key index, or deletes the journal
+// entries that the index checkpoint covers, which the new leader may need.
+//
+// The leader also holds a lease in the bucket, which it renews. Servers
+// started with --standby wait for the lease to lapse before they open the
+// DB, so that a standby takes over from a leader that died, but not from one
+// that is alive. The bucket has no conditional writes, so two standbys may
+// both take the lease; the one that opens the DB last fences the other. A
+// server started without --standby takes the lease at once.
+
+const (
+	// leaseObject is the name of the lease, relative to the DB path.
+	leaseObject = "leader.json"
+
+	defaultLeaseTTL = 10 * time.Second
+
+	// fenceCheckInterval is how often the leader compares its writer epoch
+	// with that of the latest manifest.
+	fenceCheckInterval = time.Second
+
+	// leaseSettleTime is how long a standby waits after taking the lease
+	// before checking that another standby did not take it too.
+	leaseSettleTime = time.Second
+)
+
+// leaderState is the role of a server.
+type leaderState string
+
+const (
+	stateStandby leaderState = "standby"
+	stateLeader  leaderState = "leader"
+	stateFenced  leaderState = "fenced"
+	stateReplica leaderState = "replica"
+)
+
+// lease is the leader lease, stored as JSON.
+type lease struct {
+	Holder      string    `json:"holder"`
+	WriterEpoch uint64    `json:"writer_epoch"`
+	ExpireAt    time.Time `json:"expire_at"`
+}
+
+// leadership is the role of this server and what it knows of the lease.
+type leadership struct {
+	id     string
+	ttl    time.Duration
+	bucket objstore.Bucket
+	name   string // of the lease object
+
+	mu       sync.Mutex
+	state    leaderState
+	epoch    uint64    // the writer epoch the leader opened the DB with
+	holder   string    // of the lease, as last seen
+	expireAt time.Time // of the lease, as last seen
+
+	// fenced is closed when the leader is fenced, and draining when the
+	// server shuts down.
+	fenced   chan struct{}
+	draining chan struct{}
+}
+
+func newLeadership(bucket objstore.Bucket, dbPath string, ttl time.Duration, state leaderState) *leadership {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "server"
+	}
+	return &leadership{
+		id:       fmt.Sprintf("%s-%d", host, os.Getpid()),
+		ttl:      ttl,
+		bucket:   bucket,
+		name:     path.Join(dbPath, leaseObject),
+		state:    state,
+		fenced:   make(chan struct{}),
+		draining: make(chan struct{}),
+	}
+}
+
+// readLease returns the lease and whether there is one.
+func (l *leadership) readLease(ctx context.Context) (lease, bool, error) {
+	rc, err := l.bucket.Get(ctx, l.name)
+	if l.bucket.IsObjNotFoundErr(err) {
+		return lease{}, false, nil
+	}
+	if err != nil {
+		return lease{}, false, fmt.Errorf("failed to read lease: %v", err)
+	}
+	defer rc.Close()
+
+	var current lease
+	if err := json.NewDecoder(rc).Decode(&current); err != nil {
+		return lease{}, false, fmt.Errorf("failed to decode lease: %v", err)
+	}
+	l.observe(current)
+	return current, true, nil
+}
+
+// writeLease takes or renews the lease until ttl from now, or releases it
+// if ttl is 0.
+func (l *leadership) writeLease(ctx context.Context, ttl time.Duration) error {
+	l.mu.Lock()
+	current := lease{Holder: l.id, WriterEpoch: l.epoch, ExpireAt: time.Now().Add(ttl)}
+	l.mu.Unlock()
+
+	data, err := json.Marshal(current)
+	if err != nil {
+		return fmt.Errorf("failed to encode lease: %v", err)
+	}
+	if err := l.bucket.Upload(ctx, l.name, bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("failed to write lease: %v", err)
+	}
+	l.observe(current)
+	return nil
+}
+
+func (l *leadership) observe(current lease) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.holder, l.expireAt = current.Holder, current.ExpireAt
+}
+
+// waitForLease waits until the lease lapses and takes it.
+func (l *leadership) waitForLease(ctx context.Context, logger log.Logger) error {
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+
+	lastHolder := ""
+	for {
+		current, found, err := l.readLease(ctx)
+		switch {
+		case err != nil:
+			level.Warn(logger).Log("msg", "failed to check the leader lease", "err", err)
+		case found && current.Holder != l.id && time.Now().Before(current.ExpireAt):
+			if current.Holder != lastHolder {
+				level.Info(logger).Log("msg", "standing by", "leader", current.Holder, "lease_expire_at", current.ExpireAt)
+				lastHolder = current.Holder
+			}
+		default:
+			taken, err := l.takeLease(ctx)
+			if err != nil {
+				level.Warn(logger).Log("msg", "failed to take the leader lease", "err", err)
+			}
+			if taken {
+				level.Info(logger).Log("msg", "took the leader lease", "previous", current.Holder)
+				return nil
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// takeLease writes the lease and reports whether this server still holds
+// it a moment later.
+func (l *leadership) takeLease(ctx context.Context) (bool, error) {
+	if err := l.writeLease(ctx, l.ttl); err != nil {
+		return false, err
+	}
+	select {
+	case <-time.After(leaseSettleTime):
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+	current, found, err := l.readLease(ctx)
+	if err != nil {
+		return false, err
+	}
+	return found && current.Holder == l.id, nil
+}
+
+// lead makes this server the leader with the writer epoch it opened the DB
+// with.
+func (l *leadership) lead(ctx context.Context, epoch uint64) error {
+	l.mu.Lock()
+	l.state, l.epoch = stateLeader, epoch
+	l.mu.Unlock()
+	return l.writeLease(ctx, l.ttl)
+}
+
+// release lets a standby take over at once.
+func (l *leadership) release(ctx context.Context) error {
+	return l.writeLease(ctx, 0)
+}
+
+// writable reports whether this server is the leader and holds an unexpired
+// lease.
+func (l *leadership) writable() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.state == stateLeader && l.holder == l.id && time.Now().Before(l.expireAt)
+}
+
+// serving reports whether the server serves requests: it is a replica, or
+// the leader with an unexpired lease, and does not drain.
+func (l *leadership) serving() bool {
+	select {
+	case <-l.draining:
+		return false
+	default:
+	}
+	state := l.currentState()
+	return state == stateReplica || state == stateLeader && l.writable()
+}
+
+// drain marks the server as shutting down.
+func (l *leadership) drain() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	select {
+	case <-l.draining:
+	default:
+		close(l.draining)
+	}
+}
+
+func (l *leadership) currentState() leaderState {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.state
+}
+
+func (l *leadership) proto() *pb.LeaderStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	stats := &pb.LeaderStats{
+		State:       string(l.state),
+		InstanceId:  l.id,
+		Holder:      l.holder,
+		WriterEpoch: l.epoch,
+	}
+	if !l.expireAt.IsZero() {
+		stats.LeaseExpireAt = l.expireAt.Unix()
+	}
+	return stats
+}
+
+// ServeHTTP reports the state of the server as JSON, with status 200 if it
+// serves requests and 503 otherwise, including while it drains.
+func (l *leadership) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	stats := l.proto()
+	code := http.StatusServiceUnavailable
+	if l.serving() {
+		code = http.StatusOK
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"state":           stats.State,
+		"instance_id":     stats.InstanceId,
+		"holder":          stats.Holder,
+		"writer_epoch":    stats.WriterEpoch,
+		"lease_expire_at": stats.LeaseExpireAt,
+	})
+}
+
+// fence stops the leader from serving. It returns false if the server was
+// not the leader.
+func (s *server) fence(reason string) bool {
+	l := s.leader
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.state != stateLeader {
+		return false
+	}
+	l.state = stateFenced
+	close(l.fenced)
+	level.Error(s.logger).Log("msg", "fenced by another writer, no longer serving", "reason", reason)
+	return true
+}
+
+// errFenced is returned by checkEpoch once a newer writer opened the DB.
+var errFenced = errors.New("fenced by a newer writer")
+
+// checkEpoch fences the leader if a newer writer opened the DB, and then
+// returns errFenced.
+func (s *server) checkEpoch(ctx context.Context) error {
+	epoch, err := s.writerEpoch(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check the writer epoch: %v", err)
+	}
+	if epoch > s.leader.epoch {
+		s.fence(fmt.Sprintf("writer epoch %d is newer than %d", epoch, s.leader.epoch))
+		return errFenced
+	}
+	return nil
+}
+
+// manifestCursor is the latest manifest that writerEpoch read. slatedb-go
+// writes manifests with consecutive IDs and never deletes them, so the
+// manifests written since are found by probing the next IDs rather than by
+// listing the DB, which holds every SST and WAL object too.
+type manifestCursor struct {
+	mu    sync.Mutex
+	id    uint64 // 0 until the DB was listed
+	epoch uint64
+}
+
+func (s *server) manifestName(id uint64) string {
+	return path.Join(s.dbPath, fmt.Sprintf("%020d.manifest", id))
+}
+
+// writerEpoch returns the writer epoch of the latest manifest.
+func (s *server) writerEpoch(ctx context.Context) (uint64, error) {
+	c := &s.manifests
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	id := c.id
+	if id == 0 {
+		latest := ""
+		err := s.bucket.Iter(ctx, s.dbPath+"/", func(name string) error {
+			// Manifest IDs are zero-padded, so the latest sorts last.
+			if path.Ext(name) == ".manifest" && name > latest {
+				latest = name
+			}
+			return nil
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to list manifests: %v", err)
+		}
+		if latest == "" {
+			return 0, fmt.Errorf("no manifest in %s", s.dbPath)
+		}
+		if id, err = strconv.ParseUint(strings.TrimSuffix(path.Base(latest), ".manifest"), 10, 64); err != nil {
+			return 0, fmt.Errorf("bad manifest name %s", latest)
+		}
+	} else {
+		for {
+			exists, err := s.bucket.Exists(ctx, s.manifestName(id+1))
+			if err != nil {
+				return 0, fmt.Errorf("failed to check for a manifest: %v", err)
+			}
+			if !exists {
+				break
+			}
+			id++
+		}
+		if id == c.id {
+			return c.epoch, nil
+		}
+	}
+
+	manifest, err := s.readManifest(ctx, s.manifestName(id))
+	if err != nil {
+		return 0, err
+	}
+	c.id, c.epoch = id, manifest.WriterEpoch()
+	return c.epoch, nil
+}
+
+// leaderLoop fences the leader when a newer writer opened the DB or took
+// the lease, and renews the lease otherwise.
+func (s *server) leaderLoop() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(fenceCheckInterval)
+	defer ticker.Stop()
+	renewed := time.Now()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-s.done:
+			return
+		}
+
+		ctx := context.Background()
+		if err := s.checkEpoch(ctx); errors.Is(err, errFenced) {
+			return
+		} else if err != nil {
+			level.Warn(s.logger).Log("msg", "failed to check the writer epoch", "err", err)
+		}
+
+		if time.Since(renewed) < s.leader.ttl/3 {
+			continue
+		}
+		current, found, err := s.leader.readLease(ctx)
+		if err != nil {
+			level.Warn(s.logger).Log("msg", "failed to renew the leader lease", "err", err)
+			continue
+		}
+		if found && current.Holder != s.leader.id && time.Now().Before(current.ExpireAt) {
+			s.fence(fmt.Sprintf("the leader lease is held by %s", current.Holder))
+			return
+		}
+		if err := s.leader.writeLease(ctx, s.leader.ttl); err != nil {
+			level.Warn(s.logger).Log("msg", "failed to renew the leader lease", "err", err)
+			continue
+		}
+		renewed = time.Now()
+	}
+}
+
+// checkLeader rejects every request but health checks once the leader is
+// fenced, and writes while its lease has lapsed.
+func (s *server) checkLeader(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (interface{}, error) {
+	if isHealthMethod(info.FullMethod) {
+		return handler(ctx, req)
+	}
+	if s.leader.currentState() == stateFenced {
+		return nil, notLeader(reasonFenced, "this server was fenced by a newer writer")
+	}
+
+	spans, _ := accessOf(req)
+	for _, span := range spans {
+		if (span.op == opWrite || span.op == opAdmin) && !s.leader.writable() {
+			return nil, notLeader(reasonLeaseExpired, "this server could not renew its leader lease")
+		}
+	}
+	return handler(ctx, req)
+}
+
+func (s *server) checkLeaderStream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler) error {
+	if !isHealthMethod(info.FullMethod) && s.leader.currentState() == stateFenced {
+		return notLeader(reasonFenced, "this server was fenced by a newer writer")
+	}
+	return handler(srv, ss)
+}
+
+// notLeader returns the UNAVAILABLE error of a request to a server that is
+// not the leader, so that clients try another.
+func notLeader(reason, msg string) error {
+	return failure(codes.Unavailable, reason, nil, msg)
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	pb "github.com/TFMV/slatedb_demo/proto/v2"
+	"github.com/thanos-io/objstore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// listingBucket counts the listings of the DB.
+type listingBucket struct {
+	objstore.Bucket
+	lists int
+}
+
+func (b *listingBucket) Iter(ctx context.Context, dir string, f func(string) error, options ...objstore.IterOption) error {
+	if dir == testDBPath+"/" {
+		b.lists++
+	}
+	return b.Bucket.Iter(ctx, dir, f, options...)
+}
+
+func TestFencedByNewerWriter(t *testing.T) {
+	ctx := context.Background()
+	bucket := objstore.NewInMemBucket()
+	old := openTestServer(t, bucket)
+	defer old.Close()
+
+	mustPut(t, old, "a", "1")
+	if err := old.checkpoint(ctx); err != nil {
+		t.Fatalf("checkpoint failed: %v", err)
+	}
+	mustPut(t, old, "c", "3")
+
+	// A checkpoint that old is still saving when the new writer opens the DB.
+	inFlight, _ := old.index.checkpoint()
+	inFlight.Journal = old.journal.position()
+
+	probe := &server{bucket: &listingBucket{Bucket: bucket}, dbPath: testDBPath}
+	before, err := probe.writerEpoch(ctx)
+	if err != nil {
+		t.Fatalf("writerEpoch failed: %v", err)
+	}
+
+	s := openTestServer(t, bucket)
+	if s.leader.epoch <= old.leader.epoch {
+		t.Fatalf("new writer epoch %d, want more than %d", s.leader.epoch, old.leader.epoch)
+	}
+
+	// The new epoch is found without listing the DB again.
+	if after, err := probe.writerEpoch(ctx); err != nil || after != s.leader.epoch {
+		t.Errorf("writerEpoch = %d, %v after the new writer opened the DB, want %d (was %d)", after, err, s.leader.epoch, before)
+	}
+	if lists := probe.bucket.(*listingBucket).lists; lists != 1 {
+		t.Errorf("writerEpoch listed the DB %d times, want once", lists)
+	}
+
+	if err := old.checkEpoch(ctx); !errors.Is(err, errFenced) {
+		t.Fatalf("checkEpoch of the old writer = %v, want %v", err, errFenced)
+	}
+	if state := old.leader.currentState(); state != stateFenced {
+		t.Errorf("old writer is %s, want %s", state, stateFenced)
+	}
+	_, err = old.checkLeader(ctx, &pb.GetRequest{Key: []byte("a")},
+		&grpc.UnaryServerInfo{FullMethod: pb.SlateDB_Get_FullMethodName},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			t.Error("the fenced writer served a request")
+			return nil, nil
+		})
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("request to the fenced writer: %v, want UNAVAILABLE", err)
+	}
+
+	// The new leader replaced the checkpoint of the old epoch with its own.
+	mustPut(t, s, "b", "2")
+	if err := s.checkpoint(ctx); err != nil {
+		t.Fatalf("checkpoint failed: %v", err)
+	}
+	latest := indexCheckpointName(testDBPath, s.leader.epoch)
+	names, err := listIndexCheckpoints(ctx, bucket, testDBPath)
+	if err != nil || !slices.Equal(names, []string{latest}) {
+		t.Errorf("index checkpoints = %q, %v; want %q", names, err, latest)
+	}
+
+	// The checkpoint of the old writer lands late, and does not replace
+	// that of the new leader, whose journal entries are trimmed.
+	if err := old.index.save(ctx, bucket, indexCheckpointName(testDBPath, old.leader.epoch), inFlight); err != nil {
+		t.Fatalf("failed to save the in-flight checkpoint: %v", err)
+	}
+	crash(t, s)
+	s = openTestServer(t, bucket)
+	defer s.Close()
+	if got, want := s.index.scan("", "", 0), []string{"a", "b", "c"}; !slices.Equal(got, want) {
+		t.Errorf("index holds %q, want %q", got, want)
+	}
+	wantValue(t, s, "b", []byte("2"))
+}
+
+func TestListIndexCheckpoints(t *testing.T) {
+	ctx := context.Background()
+	bucket := objstore.NewInMemBucket()
+	for _, name := range []string{
+		indexCheckpointName(testDBPath, 10),
+		indexCheckpointName(testDBPath, 9),
+		testDBPath + "/index/keys.gob",
+		testDBPath + "/index/other",
+		testDBPath + "/00000000000000000001.manifest",
+	} {
+		if err := bucket.Upload(ctx, name, strings.NewReader("")); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	names, err := listIndexCheckpoints(ctx, bucket, testDBPath)
+	want := []string{
+		testDBPath + "/index/keys.gob",
+		testDBPath + "/index/keys-00000000000000000009.gob",
+		testDBPath + "/index/keys-00000000000000000010.gob",
+	}
+	if err != nil || !slices.Equal(names, want) {
+		t.Errorf("listIndexCheckpoints = %q, %v; want %q", names, err, want)
+	}
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"unicode/utf8"
+
+	pbv1 "github.com/TFMV/slatedb_demo/proto"
+	pb "github.com/TFMV/slatedb_demo/proto/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// legacyServer serves the original string-based slatedb API by converting
+// requests to slatedb.v2 and back, in the default namespace. Binary data
+// written through v2 cannot be represented in v1 strings and is rejected
+// with FAILED_PRECONDITION.
+type legacyServer struct {
+	pbv1.UnimplementedSlateDBServer
+
+	s *server
+}
+
+// Basic operations
+func (l *legacyServer) Put(ctx context.Context, req *pbv1.PutRequest) (*pbv1.PutResponse, error) {
+	resp, err := l.s.Put(ctx, &pb.PutRequest{Key: []byte(req.Key), Value: []byte(req.Value)})
+	if err != nil {
+		return nil, err
+	}
+	return &pbv1.PutResponse{Message: resp.Message}, nil
+}
+
+func (l *legacyServer) Get(ctx context.Context, req *pbv1.GetRequest) (*pbv1.GetResponse, error) {
+	resp, err := l.s.Get(ctx, &pb.GetRequest{Key: []byte(req.Key)})
+	if status.Code(err) == codes.NotFound {
+		// v1 clients expect an empty value for a missing key.
+		return &pbv1.GetResponse{Message: fmt.Sprintf("Key '%s' not found", req.Key)}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := legacyString(resp.Value)
+	if err != nil {
+		return nil, err
+	}
+	return &pbv1.GetResponse{Value: value, Message: resp.Message}, nil
+}
+
+func (l *legacyServer) Delete(ctx context.Context, req *pbv1.DeleteRequest) (*pbv1.DeleteResponse, error) {
+	resp, err := l.s.Delete(ctx, &pb.DeleteRequest{Key: []byte(req.Key)})
+	if err != nil {
+		return nil, err
+	}
+	return &pbv1.DeleteResponse{Message: resp.Message}, nil
+}
+
+// Batch operations
+func (l *legacyServer) BatchPut(ctx context.Context, req *pbv1.BatchPutRequest) (*pbv1.BatchPutResponse, error) {
+	entries := make([]*pb.KeyValue, len(req.Entries))
+	for i, entry := range req.Entries {
+		entries[i] = &pb.KeyValue{Key: []byte(entry.Key), Value: []byte(entry.Value)}
+	}
+
+	resp, err := l.s.BatchPut(ctx, &pb.BatchPutRequest{Entries: entries})
+	if err != nil {
+		return nil, err
+	}
+	return &pbv1.BatchPutResponse{
+		Message:      resp.Message,
+		SuccessCount: resp.SuccessCount,
+		FailureCount: resp.FailureCount,
+	}, nil
+}
+
+func (l *legacyServer) BatchGet(ctx context.Context, req *pbv1.BatchGetRequest) (*pbv1.BatchGetResponse, error) {
+	resp, err := l.s.BatchGet(ctx, &pb.BatchGetRequest{Keys: toBytes(req.Keys)})
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := legacyEntries(resp.Entries)
+	if err != nil {
+		return nil, err
+	}
+	missing := make([]string, len(resp.MissingKeys))
+	for i, key := range resp.MissingKeys {
+		missing[i] = string(key)
+	}
+
+	return &pbv1.BatchGetResponse{
+		Entries:     entries,
+		MissingKeys: missing,
+		Message:     resp.Message,
+	}, nil
+}
+
+func (l *legacyServer) BatchDelete(ctx context.Context, req *pbv1.BatchDeleteRequest) (*pbv1.BatchDeleteResponse, error) {
+	resp, err := l.s.BatchDelete(ctx, &pb.BatchDeleteRequest{Keys: toBytes(req.Keys)})
+	if err != nil {
+		return nil, err
+	}
+	return &pbv1.BatchDeleteResponse{
+		Message:      resp.Message,
+		SuccessCount: resp.SuccessCount,
+		FailureCount: resp.FailureCount,
+	}, nil
+}
+
+// Scanning operations
+func (l *legacyServer) PrefixScan(ctx context.Context, req *pbv1.PrefixScanRequest) (*pbv1.PrefixScanResponse, error) {
+	resp, err := l.s.PrefixScan(ctx, &pb.PrefixScanRequest{
+		Prefix:    []byte(req.Prefix),
+		Limit:     req.Limit,
+		PageToken: req.PageToken,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := legacyEntries(resp.Entries)
+	if err != nil {
+		return nil, err
+	}
+	return &pbv1.PrefixScanResponse{
+		Entries:       entries,
+		Message:       resp.Message,
+		NextPageToken: resp.NextPageToken,
+	}, nil
+}
+
+func (l *legacyServer) RangeScan(ctx context.Context, req *pbv1.RangeScanRequest) (*pbv1.RangeScanResponse, error) {
+	resp, err := l.s.RangeScan(ctx, &pb.RangeScanRequest{
+		StartKey:  []byte(req.StartKey),
+		EndKey:    []byte(req.EndKey),
+		Limit:     req.Limit,
+		PageToken: req.PageToken,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := legacyEntries(resp.Entries)
+	if err != nil {
+		return nil, err
+	}
+	return &pbv1.RangeScanResponse{
+		Entries:       entries,
+		Message:       resp.Message,
+		NextPageToken: resp.NextPageToken,
+	}, nil
+}
+
+func (l *legacyServer) PrefixScanStream(req *pbv1.PrefixScanRequest, stream pbv1.SlateDB_PrefixScanStreamServer) error {
+	start, end, err := prefixRange(defaultNamespace, []byte(req.Prefix), req.PageToken)
+	if err != nil {
+		return err
+	}
+	return l.s.scanStream(stream.Context(), defaultNamespace, nil, start, end, int(req.Limit), legacySend(stream.Send))
+}
+
+func (l *legacyServer) RangeScanStream(req *pbv1.RangeScanRequest, stream pbv1.SlateDB_RangeScanStreamServer) error {
+	start, end, err := keyRange(defaultNamespace, []byte(req.StartKey), []byte(req.EndKey), req.PageToken)
+	if err != nil {
+		return err
+	}
+	return l.s.scanStream(stream.Context(), defaultNamespace, nil, start, end, int(req.Limit), legacySend(stream.Send))
+}
+
+// Statistics and monitoring
+func (l *legacyServer) GetStats(ctx context.Context, req *pbv1.GetStatsRequest) (*pbv1.GetStatsResponse, error) {
+	resp, err := l.s.GetStats(ctx, &pb.GetStatsRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return &pbv1.GetStatsResponse{
+		TotalKeys:      resp.TotalKeys,
+		TotalSizeBytes: resp.TotalSizeBytes,
+		DbPath:         resp.DbPath,
+		Message:        resp.Message,
+	}, nil
+}
+
+// legacySend adapts a v1 stream's Send to the chunks produced by scanStream.
+func legacySend(send func(*pbv1.ScanChunk) error) func(*pb.ScanChunk) error {
+	return func(chunk *pb.ScanChunk) error {
+		entries, err := legacyEntries(chunk.Entries)
+		if err != nil {
+			return err
+		}
+		return send(&pbv1.ScanChunk{Entries: entries})
+	}
+}
+
+func legacyEntries(entries []*pb.KeyValue) ([]*pbv1.KeyValue, error) {
+	result := make([]*pbv1.KeyValue, len(entries))
+	for i, entry := range entries {
+		key, err := legacyString(entry.Key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := legacyString(entry.Value)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = &pbv1.KeyValue{Key: key, Value: value}
+	}
+	return result, nil
+}
+
+// legacyString converts b to a v1 string field, which must be valid UTF-8.
+func legacyString(b []byte) (string, error) {
+	if !utf8.Valid(b) {
+		return "", failure(codes.FailedPrecondition, reasonBinaryData, nil,
+			"binary data cannot be returned by the slatedb API, use slatedb.v2")
+	}
+	return string(b), nil
+}
+
+func toBytes(keys []string) [][]byte {
+	result := make([][]byte, len(keys))
+	for i, key := range keys {
+		result[i] = []byte(key)
+	}
+	return result
+}
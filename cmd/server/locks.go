@@ -0,0 +1,29 @@
+package main
+
+import (
+	"hash/maphash"
+	"sync"
+)
+
+// keyLockStripes is the number of mutexes shared by all keys. Keys that hash
+// to the same stripe are serialized with each other, which is harmless
+// beyond the extra waiting.
+const keyLockStripes = 256
+
+// keyLocks serializes writes to the same key, so that the check and the write
+// of a conditional operation cannot interleave with another write.
+type keyLocks struct {
+	seed    maphash.Seed
+	stripes [keyLockStripes]sync.Mutex
+}
+
+func newKeyLocks() *keyLocks {
+	return &keyLocks{seed: maphash.MakeSeed()}
+}
+
+// lock locks key and returns the function that unlocks it.
+func (l *keyLocks) lock(key []byte) func() {
+	mu := &l.stripes[maphash.Bytes(l.seed, key)%keyLockStripes]
+	mu.Lock()
+	return mu.Unlock
+}
@@ -0,0 +1,296 @@
+// Command server exposes a SlateDB database over gRPC. It serves the
+// binary-safe slatedb.v2 API as well as the original slatedb API.
+//
+// The database lives in an object storage bucket and is configured through
+// the environment:
+//
+//   - PORT: the port to listen on (default "5423")
+//   - BUCKET_CONFIG: a thanos-style bucket configuration in YAML
+//   - BUCKET_CONFIG_FILE: a file containing the bucket configuration
+//   - BUCKET_NAME: a GCS bucket, used when no bucket configuration is set
+//   - DB_PATH: the path of the database inside the bucket (default "slatedb_demo")
+//   - TLS_CERT_FILE, TLS_KEY_FILE: the PEM certificate and key to serve TLS with
+//   - TLS_CLIENT_CA_FILE: CA certificates that client certificates must be
+//     signed by; setting it requires clients to present one (mutual TLS)
+//   - AUTH_CONFIG_FILE: a YAML file with the API keys, JWT settings and ACL
+//     rules that requests are checked against; see auth.go
+//
+// Without TLS_CERT_FILE the server accepts plaintext connections only. The
+// TLS files are reloaded when they change.
+//
+// With --read-only the server is a replica: it serves reads from the bucket
+// of a primary server, which must be running, and rejects writes with
+// FAILED_PRECONDITION. It reopens the database every --refresh-interval to
+// see the writes of the primary; see replica.go.
+//
+// Only one server may write to the bucket. A server fences any other that
+// writes to it, which then exits, and holds a lease that it renews every
+// --lease-ttl. With --standby the server waits for the lease to lapse before
+// it takes over; see leader.go. --health-addr serves the role of the server
+// at /healthz, with status 503 unless it serves requests.
+//
+// The server also implements the grpc.health.v1 service and server
+// reflection. On SIGTERM it reports NOT_SERVING, keeps serving for
+// --drain-delay so that load balancers notice, waits up to --drain-timeout
+// for the RPCs in flight, then flushes and closes the database.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/TFMV/slatedb_demo/internal/tlsconfig"
+	pbv1 "github.com/TFMV/slatedb_demo/proto"
+	pb "github.com/TFMV/slatedb_demo/proto/v2"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+const (
+	defaultPort   = "5423"
+	defaultDBPath = "slatedb_demo"
+
+	// certReloadInterval is how often the TLS files are checked for changes.
+	certReloadInterval = 10 * time.Second
+
+	defaultDrainDelay   = 3 * time.Second
+	defaultDrainTimeout = 20 * time.Second
+)
+
+func main() {
+	logger := log.NewLogfmtLogger(log.NewSyncWriter(os.Stderr))
+	logger = log.With(logger, "ts", log.DefaultTimestampUTC, "caller", log.DefaultCaller)
+
+	if err := run(logger); err != nil {
+		level.Error(logger).Log("msg", "server failed", "err", err)
+		os.Exit(1)
+	}
+}
+
+func run(logger log.Logger) error {
+	readOnly := flag.Bool("read-only", false, "serve reads only, as a replica of the primary that writes to the bucket")
+	refreshInterval := flag.Duration("refresh-interval", defaultRefreshInterval, "how often a read-only replica reopens the database")
+	standby := flag.Bool("standby", false, "wait for the leader lease to lapse before taking over the database")
+	leaseTTL := flag.Duration("lease-ttl", defaultLeaseTTL, "how long the leader lease lasts unless it is renewed")
+	healthAddr := flag.String("health-addr", "", "address to serve the /healthz endpoint on, such as :8080")
+	drainDelay := flag.Duration("drain-delay", defaultDrainDelay, "how long to keep serving after reporting NOT_SERVING on shutdown")
+	drainTimeout := flag.Duration("drain-timeout", defaultDrainTimeout, "how long to wait for RPCs in flight on shutdown")
+	flag.Parse()
+
+	var replica *replicaOptions
+	if *readOnly {
+		if *standby {
+			return errors.New("--standby and --read-only are mutually exclusive")
+		}
+		if *refreshInterval <= 0 {
+			return errors.New("--refresh-interval must be positive")
+		}
+		replica = &replicaOptions{refreshInterval: *refreshInterval}
+	}
+	if *leaseTTL < 3*fenceCheckInterval {
+		return fmt.Errorf("--lease-ttl must be at least %v", 3*fenceCheckInterval)
+	}
+	if *drainDelay < 0 {
+		return errors.New("--drain-delay cannot be negative")
+	}
+	if *drainTimeout <= 0 {
+		return errors.New("--drain-timeout must be positive")
+	}
+
+	port := getEnv("PORT", defaultPort)
+	dbPath := getEnv("DB_PATH", defaultDBPath)
+	bucketConf, err := loadBucketConfig()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	bucket, provider, err := newBucket(ctx, logger, bucketConf)
+	if err != nil {
+		return err
+	}
+	defer bucket.Close()
+
+	leader := newLeadership(bucket, dbPath, *leaseTTL, stateStandby)
+	if replica != nil {
+		leader.state = stateReplica
+	}
+	if *healthAddr != "" {
+		health, err := serveHealth(logger, *healthAddr, leader)
+		if err != nil {
+			return err
+		}
+		defer health.Close()
+	}
+
+	if *standby {
+		waitCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		err := leader.waitForLease(waitCtx, logger)
+		stop()
+		if err != nil && waitCtx.Err() != nil {
+			level.Info(logger).Log("msg", "shutting down while standing by")
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	srv, err := newServer(ctx, logger, bucket, dbPath, replica, leader)
+	if err != nil {
+		return err
+	}
+
+	creds, err := loadTLS(logger)
+	if err != nil {
+		srv.Close()
+		return err
+	}
+
+	lis, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		srv.Close()
+		return err
+	}
+
+	serverOpts := []grpc.ServerOption{grpc.Creds(creds)}
+	if path := os.Getenv("AUTH_CONFIG_FILE"); path != "" {
+		auth, err := loadAuth(logger, path)
+		if err != nil {
+			lis.Close()
+			srv.Close()
+			return err
+		}
+		serverOpts = append(serverOpts,
+			grpc.ChainUnaryInterceptor(auth.unaryInterceptor),
+			grpc.ChainStreamInterceptor(auth.streamInterceptor))
+		level.Info(logger).Log("msg", "authentication enabled", "api_keys", len(auth.apiKeys),
+			"jwt", auth.jwtParser != nil, "rules", len(auth.rules))
+	} else {
+		level.Warn(logger).Log("msg", "serving without authentication; set AUTH_CONFIG_FILE to enable it")
+	}
+	serverOpts = append(serverOpts, grpc.ChainStreamInterceptor(leader.endWatchesOnDrain))
+	if replica != nil {
+		serverOpts = append(serverOpts,
+			grpc.ChainUnaryInterceptor(srv.rejectWrites),
+			grpc.ChainStreamInterceptor(srv.rejectWatches))
+	} else {
+		serverOpts = append(serverOpts,
+			grpc.ChainUnaryInterceptor(srv.checkLeader),
+			grpc.ChainStreamInterceptor(srv.checkLeaderStream))
+	}
+
+	grpcServer := grpc.NewServer(serverOpts...)
+	pb.RegisterSlateDBServer(grpcServer, srv)
+	pbv1.RegisterSlateDBServer(grpcServer, &legacyServer{s: srv})
+	hs := health.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, hs)
+	reflection.Register(grpcServer)
+
+	healthDone := make(chan struct{})
+	defer close(healthDone)
+	go reportHealth(logger, hs, leader, healthDone)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		level.Info(logger).Log("msg", "server listening", "addr", lis.Addr(), "bucket", provider, "db_path", dbPath,
+			"read_only", replica != nil)
+		serveErr <- grpcServer.Serve(lis)
+	}()
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+
+	select {
+	case s := <-sig:
+		level.Info(logger).Log("msg", "shutting down", "signal", s)
+		srv.StopWatches()
+		drain(logger, grpcServer, hs, leader, *drainDelay, *drainTimeout)
+	case <-leader.fenced:
+		// The DB belongs to the new leader: stop at once, flushing nothing.
+		hs.Shutdown()
+		grpcServer.Stop()
+		err = errors.New("fenced by a newer writer")
+	case err = <-serveErr:
+	}
+
+	if cerr := srv.Close(); cerr != nil && err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// serveHealth serves the role of the server at /healthz on addr.
+func serveHealth(logger log.Logger, addr string, leader *leadership) (*http.Server, error) {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/healthz", leader)
+	health := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		if err := health.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			level.Error(logger).Log("msg", "health endpoint failed", "err", err)
+		}
+	}()
+	level.Info(logger).Log("msg", "serving health", "addr", lis.Addr())
+	return health, nil
+}
+
+// loadTLS returns the transport credentials configured by the TLS_*
+// variables, or plaintext credentials if TLS_CERT_FILE is not set.
+func loadTLS(logger log.Logger) (credentials.TransportCredentials, error) {
+	files := tlsconfig.Files{
+		CertFile: os.Getenv("TLS_CERT_FILE"),
+		KeyFile:  os.Getenv("TLS_KEY_FILE"),
+		CAFile:   os.Getenv("TLS_CLIENT_CA_FILE"),
+	}
+	if files.CertFile == "" && files.KeyFile == "" {
+		if files.CAFile != "" {
+			return nil, errors.New("TLS_CLIENT_CA_FILE requires TLS_CERT_FILE and TLS_KEY_FILE")
+		}
+		level.Warn(logger).Log("msg", "serving without TLS; set TLS_CERT_FILE and TLS_KEY_FILE to enable it")
+		return insecure.NewCredentials(), nil
+	}
+
+	reloader, err := tlsconfig.NewReloader(files, certReloadInterval, func(err error) {
+		if err != nil {
+			level.Error(logger).Log("msg", "failed to reload TLS certificates", "err", err)
+			return
+		}
+		level.Info(logger).Log("msg", "reloaded TLS certificates")
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS certificates: %v", err)
+	}
+	cfg, err := reloader.ServerConfig()
+	if err != nil {
+		reloader.Close()
+		return nil, err
+	}
+
+	level.Info(logger).Log("msg", "serving TLS", "cert", files.CertFile, "mutual", files.CAFile != "")
+	return credentials.NewTLS(cfg), nil
+}
+
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,315 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/TFMV/slatedb_demo/proto/v2"
+	"github.com/go-kit/log/level"
+	"github.com/slatedb/slatedb-go/slatedb"
+	"github.com/slatedb/slatedb-go/slatedb/common"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Namespaces are stored in the same DB and key index as the default
+// namespace. The keys of namespace n are stored under the prefix 0x00 n 0x00,
+// and the default namespace holds every key that does not start with 0x00,
+// so each namespace is one contiguous range of stored keys. The names of the
+// namespaces are kept in the DB under the key 0x00, which belongs to none of
+// them and is not indexed.
+
+const (
+	// registryKey is the DB key of the namespace registry.
+	registryKey = "\x00"
+
+	maxNamespaceLen = 64
+)
+
+// namespace maps the keys of one namespace to the keys stored in the DB.
+type namespace struct {
+	name   string
+	prefix string // empty for the default namespace
+}
+
+var defaultNamespace = namespace{}
+
+func newNamespace(name string) namespace {
+	if name == "" {
+		return defaultNamespace
+	}
+	return namespace{name: name, prefix: "\x00" + name + "\x00"}
+}
+
+// namespaceOf returns the name of the namespace of the stored key.
+func namespaceOf(key string) string {
+	if key == "" || key[0] != 0 {
+		return ""
+	}
+	name, _, _ := strings.Cut(key[1:], "\x00")
+	return name
+}
+
+// key returns the stored key of key.
+func (ns namespace) key(key []byte) []byte {
+	if ns.prefix == "" {
+		return key
+	}
+	return append([]byte(ns.prefix), key...)
+}
+
+// external returns the key of the stored key in ns.
+func (ns namespace) external(key string) []byte {
+	return []byte(key[len(ns.prefix):])
+}
+
+// bounds returns the range [start, end) of the stored keys of ns. An empty
+// end means no upper bound.
+func (ns namespace) bounds() (string, string) {
+	if ns.prefix == "" {
+		return "\x01", ""
+	}
+	return ns.prefix, prefixEnd(ns.prefix)
+}
+
+// contains reports whether the stored key belongs to ns.
+func (ns namespace) contains(key []byte) bool {
+	start, end := ns.bounds()
+	return string(key) >= start && (end == "" || string(key) < end)
+}
+
+// clamp restricts the range [start, end) of stored keys to ns.
+func (ns namespace) clamp(start, end string) (string, string) {
+	lo, hi := ns.bounds()
+	if start < lo {
+		start = lo
+	}
+	if hi != "" && (end == "" || end > hi) {
+		end = hi
+	}
+	return start, end
+}
+
+// checkKey validates a key of ns given by a client.
+func (ns namespace) checkKey(key []byte) error {
+	if len(key) == 0 {
+		return invalidArgument("key", "key cannot be empty")
+	}
+	if ns.prefix == "" && key[0] == 0 {
+		return invalidArgument("key", "keys of the default namespace cannot start with 0x00")
+	}
+	return nil
+}
+
+// validNamespaceName reports whether name can be given to a namespace.
+func validNamespaceName(name string) bool {
+	if name == "" || len(name) > maxNamespaceLen {
+		return false
+	}
+	return strings.IndexFunc(name, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '_' || r == '-')
+	}) < 0
+}
+
+// namespaceRegistry holds the namespaces that were created, with their
+// creation time in Unix seconds.
+type namespaceRegistry struct {
+	mu      sync.RWMutex
+	created map[string]int64
+}
+
+// replace replaces the namespaces of the registry with created.
+func (r *namespaceRegistry) replace(created map[string]int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.created = created
+}
+
+// loadNamespaces reads the namespace registry from the DB.
+func (s *server) loadNamespaces() error {
+	created, err := readNamespaces(s.db)
+	if err != nil {
+		return err
+	}
+	s.namespaces = &namespaceRegistry{created: created}
+	return nil
+}
+
+// readNamespaces returns the namespaces of the registry stored in db.
+func readNamespaces(db *slatedb.DB) (map[string]int64, error) {
+	created := make(map[string]int64)
+	data, err := db.Get([]byte(registryKey))
+	if errors.Is(err, common.ErrKeyNotFound) {
+		return created, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read namespaces: %v", err)
+	}
+	if err := json.Unmarshal(data, &created); err != nil {
+		return nil, fmt.Errorf("failed to decode namespaces: %v", err)
+	}
+	return created, nil
+}
+
+// saveNamespaces writes the namespace registry to the DB. The caller holds
+// s.namespaces.mu.
+func (s *server) saveNamespaces() error {
+	data, err := json.Marshal(s.namespaces.created)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to encode namespaces: %v", err)
+	}
+	s.db.PutWithOptions([]byte(registryKey), data, slatedb.DefaultWriteOptions())
+	return nil
+}
+
+// namespace returns the namespace called name. Writes resolve it while
+// holding commitMu, so that it cannot be dropped before they complete.
+func (s *server) namespace(name string) (namespace, error) {
+	if name == "" {
+		return defaultNamespace, nil
+	}
+
+	s.namespaces.mu.RLock()
+	defer s.namespaces.mu.RUnlock()
+	if _, ok := s.namespaces.created[name]; !ok {
+		return namespace{}, failure(codes.NotFound, reasonNamespaceNotFound, map[string]string{"namespace": name},
+			fmt.Sprintf("namespace '%s' does not exist", name))
+	}
+	return newNamespace(name), nil
+}
+
+// namespaceStats returns the number of keys of ns and their total size in
+// bytes, counting the keys as the clients see them.
+func (s *server) namespaceStats(ns namespace) (int64, int64) {
+	keys, size := s.index.namespaceStats(ns.name)
+	return keys, size - keys*int64(len(ns.prefix))
+}
+
+// prefixStats is namespaceStats for the keys of ns with prefix.
+func (s *server) prefixStats(ns namespace, prefix []byte) (int64, int64) {
+	if len(prefix) == 0 {
+		return s.namespaceStats(ns)
+	}
+	if ns.checkKey(prefix) != nil {
+		// A prefix of the reserved keys, which belong to no namespace.
+		return 0, 0
+	}
+	keys, size := s.index.prefixStats(string(ns.key(prefix)))
+	return keys, size - keys*int64(len(ns.prefix))
+}
+
+func (s *server) CreateNamespace(ctx context.Context, req *pb.CreateNamespaceRequest) (*pb.CreateNamespaceResponse, error) {
+	if !validNamespaceName(req.Name) {
+		return nil, invalidArgument("name",
+			"invalid namespace name '%s': use 1 to %d letters, digits, '.', '_' or '-'", req.Name, maxNamespaceLen)
+	}
+
+	s.namespaces.mu.Lock()
+	defer s.namespaces.mu.Unlock()
+
+	if _, ok := s.namespaces.created[req.Name]; ok {
+		return nil, failure(codes.AlreadyExists, reasonNamespaceExists, map[string]string{"namespace": req.Name},
+			fmt.Sprintf("namespace '%s' already exists", req.Name))
+	}
+	s.namespaces.created[req.Name] = time.Now().Unix()
+	if err := s.saveNamespaces(); err != nil {
+		delete(s.namespaces.created, req.Name)
+		return nil, err
+	}
+
+	level.Info(s.logger).Log("msg", "created namespace", "namespace", req.Name)
+	return &pb.CreateNamespaceResponse{
+		Message: fmt.Sprintf("Namespace '%s' created successfully", req.Name),
+	}, nil
+}
+
+func (s *server) ListNamespaces(ctx context.Context, req *pb.ListNamespacesRequest) (*pb.ListNamespacesResponse, error) {
+	s.namespaces.mu.RLock()
+	names := make([]string, 0, len(s.namespaces.created))
+	for name := range s.namespaces.created {
+		names = append(names, name)
+	}
+	s.namespaces.mu.RUnlock()
+	sort.Strings(names)
+
+	resp := &pb.ListNamespacesResponse{}
+	for _, name := range names {
+		resp.Namespaces = append(resp.Namespaces, s.namespaceStatsProto(newNamespace(name)))
+	}
+	resp.Message = fmt.Sprintf("Found %d namespaces", len(resp.Namespaces))
+	return resp, nil
+}
+
+// DropNamespace deletes the keys of the namespace before the namespace
+// itself, so that a drop that fails halfway can be retried. The keys are
+// deleted in batches, as by DeleteRange, and commitMu is held exclusively
+// only to remove the namespace.
+func (s *server) DropNamespace(ctx context.Context, req *pb.DropNamespaceRequest) (*pb.DropNamespaceResponse, error) {
+	if req.Name == "" {
+		return nil, invalidArgument("name", "the default namespace cannot be dropped")
+	}
+	ns, err := s.namespace(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	// Expired keys are left to the reaper, which deletes them whether or
+	// not their namespace still exists.
+	start, end := ns.bounds()
+	deleted, err := s.deleteRange(ctx, ns, start, end, false)
+	if err != nil {
+		return nil, err
+	}
+
+	s.commitMu.Lock()
+	defer s.commitMu.Unlock()
+
+	if _, err := s.namespace(req.Name); err != nil {
+		return nil, err
+	}
+
+	// Keys written while the batches were deleted, if any.
+	keys := s.index.scan(start, end, 0)
+	for i, key := range keys {
+		s.delete([]byte(key), slatedb.WriteOptions{AwaitFlush: i == len(keys)-1})
+	}
+	deleted += int64(len(keys))
+
+	s.namespaces.mu.Lock()
+	defer s.namespaces.mu.Unlock()
+
+	createdAt := s.namespaces.created[req.Name]
+	delete(s.namespaces.created, req.Name)
+	if err := s.saveNamespaces(); err != nil {
+		s.namespaces.created[req.Name] = createdAt
+		return nil, err
+	}
+
+	level.Info(s.logger).Log("msg", "dropped namespace", "namespace", req.Name, "keys", deleted)
+	return &pb.DropNamespaceResponse{
+		Message:     fmt.Sprintf("Namespace '%s' dropped, %d keys deleted", req.Name, deleted),
+		DeletedKeys: deleted,
+	}, nil
+}
+
+// namespaceStatsProto returns the statistics of ns.
+func (s *server) namespaceStatsProto(ns namespace) *pb.NamespaceStats {
+	keys, size := s.namespaceStats(ns)
+
+	s.namespaces.mu.RLock()
+	createdAt := s.namespaces.created[ns.name]
+	s.namespaces.mu.RUnlock()
+
+	return &pb.NamespaceStats{
+		Name:      ns.name,
+		Keys:      keys,
+		SizeBytes: size,
+		CreatedAt: createdAt,
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNamespaceBounds(t *testing.T) {
+	tests := []struct {
+		name       string
+		ns         string
+		start, end string
+	}{
+		{"default", "", "\x01", ""},
+		{"named", "tenant", "\x00tenant\x00", "\x00tenant\x01"},
+		{"single letter", "a", "\x00a\x00", "\x00a\x01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := newNamespace(tt.ns).bounds()
+			if start != tt.start || end != tt.end {
+				t.Errorf("bounds of %q = [%q, %q), want [%q, %q)", tt.ns, start, end, tt.start, tt.end)
+			}
+		})
+	}
+}
+
+func TestNamespaceContains(t *testing.T) {
+	tests := []struct {
+		name string
+		ns   string
+		key  string
+		want bool
+	}{
+		{"default holds plain keys", "", "user:1", true},
+		{"default holds 0x01 keys", "", "\x01", true},
+		{"default holds 0xff keys", "", "\xff\xff", true},
+		{"default excludes the registry", "", registryKey, false},
+		{"default excludes the journal", "", journalPrefix, false},
+		{"default excludes namespaced keys", "", "\x00a\x00k", false},
+		{"own key", "a", "\x00a\x00k", true},
+		{"own empty key", "a", "\x00a\x00", true},
+		{"own 0xff key", "a", "\x00a\x00\xff", true},
+		{"longer name", "a", "\x00ab\x00k", false},
+		{"name with dot", "a", "\x00a.b\x00k", false},
+		{"shorter name", "ab", "\x00a\x00k", false},
+		{"name without separator", "a", "\x00a", false},
+		{"next prefix", "a", "\x00a\x01", false},
+		{"plain key", "a", "a", false},
+		{"registry", "a", registryKey, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newNamespace(tt.ns).contains([]byte(tt.key)); got != tt.want {
+				t.Errorf("namespace %q contains %q = %v, want %v", tt.ns, tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNamespaceClamp(t *testing.T) {
+	tests := []struct {
+		name               string
+		ns                 string
+		start, end         string
+		wantStart, wantEnd string
+	}{
+		{"default, whole keyspace", "", "", "", "\x01", ""},
+		{"default, range inside", "", "a", "m", "a", "m"},
+		{"default, start at 0x00", "", "\x00", "b", "\x01", "b"},
+		{"default, start in a namespace", "", "\x00a\x00k", "", "\x01", ""},
+		{"named, whole keyspace", "a", "", "", "\x00a\x00", "\x00a\x01"},
+		{"named, range inside", "a", "\x00a\x00b", "\x00a\x00d", "\x00a\x00b", "\x00a\x00d"},
+		{"named, end past namespace", "a", "\x00a\x00b", "\x00b", "\x00a\x00b", "\x00a\x01"},
+		{"named, end unbounded", "a", "\x00a\x00b", "", "\x00a\x00b", "\x00a\x01"},
+		{"named, start before namespace", "a", "\x00", "\x00a\x00c", "\x00a\x00", "\x00a\x00c"},
+		{"named, end at namespace end", "a", "\x00a\x00", "\x00a\x01", "\x00a\x00", "\x00a\x01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := newNamespace(tt.ns).clamp(tt.start, tt.end)
+			if start != tt.wantStart || end != tt.wantEnd {
+				t.Errorf("namespace %q clamp(%q, %q) = [%q, %q), want [%q, %q)",
+					tt.ns, tt.start, tt.end, start, end, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestNamespaceKey(t *testing.T) {
+	ns := newNamespace("a")
+	stored := ns.key([]byte("k\x00"))
+	if string(stored) != "\x00a\x00k\x00" {
+		t.Fatalf("key = %q, want %q", stored, "\x00a\x00k\x00")
+	}
+	if !ns.contains(stored) {
+		t.Errorf("namespace does not contain its own key %q", stored)
+	}
+	if got := string(ns.external(string(stored))); got != "k\x00" {
+		t.Errorf("external(%q) = %q, want %q", stored, got, "k\x00")
+	}
+	if got := string(defaultNamespace.key([]byte("k"))); got != "k" {
+		t.Errorf("default key = %q, want %q", got, "k")
+	}
+}
+
+func TestValidNamespaceName(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"tenant", true},
+		{"Tenant-1.prod_eu", true},
+		{"a", true},
+		{strings.Repeat("a", maxNamespaceLen), true},
+		{strings.Repeat("a", maxNamespaceLen+1), false},
+		{"", false},
+		{"a\x00b", false},
+		{"\x00", false},
+		{"\x01j", false},
+		{"a b", false},
+		{"a/b", false},
+		{"*", false},
+		{"é", false},
+	}
+	for _, tt := range tests {
+		if got := validNamespaceName(tt.name); got != tt.want {
+			t.Errorf("validNamespaceName(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNamespaceCheckKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		ns      string
+		key     string
+		wantErr bool
+	}{
+		{"default plain key", "", "k", false},
+		{"default 0x01 key", "", "\x01", false},
+		{"default empty key", "", "", true},
+		{"default 0x00 key", "", "\x00a\x00k", true},
+		{"named plain key", "a", "k", false},
+		{"named 0x00 key", "a", "\x00", false},
+		{"named empty key", "a", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newNamespace(tt.ns).checkKey([]byte(tt.key))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("namespace %q checkKey(%q) = %v, want error %v", tt.ns, tt.key, err, tt.wantErr)
+			}
+		})
+	}
+}
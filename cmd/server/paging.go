@@ -0,0 +1,35 @@
+package main
+
+import "encoding/base64"
+
+// Page tokens are opaque to clients. A token encodes the last key of the
+// page it ends, so the next page starts right after that key no matter how
+// the keyspace changed in between.
+
+// pageToken returns the token for the page that follows lastKey.
+func pageToken(lastKey string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(lastKey))
+}
+
+// resumeKey returns the key a scan of [start, end) should begin at. With an
+// empty token that is start itself; otherwise it is the key following the
+// one encoded in token, which must lie within the scanned range.
+func resumeKey(token, start, end string) (string, error) {
+	if token == "" {
+		return start, nil
+	}
+
+	lastKey, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return "", invalidArgument("page_token", "invalid page token")
+	}
+	if string(lastKey) < start || (end != "" && string(lastKey) >= end) {
+		return "", invalidArgument("page_token", "page token does not belong to this scan")
+	}
+	return keyAfter(string(lastKey)), nil
+}
+
+// keyAfter returns the smallest key that sorts after key.
+func keyAfter(key string) string {
+	return key + "\x00"
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestPageTokenRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		lastKey string
+	}{
+		{"plain", "user:42"},
+		{"binary", "\x00\x01\xff\xfe"},
+		{"namespaced", "\x00tenant\x00user:1"},
+		{"url unsafe", "a/b+c=d?e"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := pageToken(tt.lastKey)
+			for _, c := range token {
+				if c == '+' || c == '/' || c == '=' {
+					t.Fatalf("pageToken(%q) = %q, want a URL-safe unpadded token", tt.lastKey, token)
+				}
+			}
+			got, err := resumeKey(token, "", "")
+			if err != nil {
+				t.Fatalf("resumeKey(%q) failed: %v", token, err)
+			}
+			if want := keyAfter(tt.lastKey); got != want {
+				t.Errorf("resumeKey(pageToken(%q)) = %q, want %q", tt.lastKey, got, want)
+			}
+		})
+	}
+}
+
+func TestResumeKey(t *testing.T) {
+	tests := []struct {
+		name       string
+		token      string
+		start, end string
+		want       string
+		wantErr    bool
+	}{
+		{name: "no token", token: "", start: "a", end: "m", want: "a"},
+		{name: "no token unbounded", token: "", start: "", end: "", want: ""},
+		{name: "inside", token: pageToken("c"), start: "a", end: "m", want: "c\x00"},
+		{name: "at start", token: pageToken("a"), start: "a", end: "m", want: "a\x00"},
+		{name: "before start", token: pageToken("Z"), start: "a", end: "m", wantErr: true},
+		{name: "at end", token: pageToken("m"), start: "a", end: "m", wantErr: true},
+		{name: "after end", token: pageToken("n"), start: "a", end: "m", wantErr: true},
+		{name: "unbounded end", token: pageToken("zzz"), start: "a", end: "", want: "zzz\x00"},
+		{name: "just below end", token: pageToken("l\xff"), start: "a", end: "m", want: "l\xff\x00"},
+		{name: "other namespace", token: pageToken("\x00b\x00k"), start: "\x00a\x00", end: "\x00a\x01", wantErr: true},
+		{name: "own namespace", token: pageToken("\x00a\x00k"), start: "\x00a\x00", end: "\x00a\x01", want: "\x00a\x00k\x00"},
+		{name: "not base64", token: "!!!", start: "", end: "", wantErr: true},
+		{name: "padded", token: "YQ==", start: "", end: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resumeKey(tt.token, tt.start, tt.end)
+			if tt.wantErr {
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("resumeKey(%q, %q, %q) = %q, %v, want INVALID_ARGUMENT", tt.token, tt.start, tt.end, got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resumeKey(%q, %q, %q) failed: %v", tt.token, tt.start, tt.end, err)
+			}
+			if got != tt.want {
+				t.Errorf("resumeKey(%q, %q, %q) = %q, want %q", tt.token, tt.start, tt.end, got, tt.want)
+			}
+		})
+	}
+}
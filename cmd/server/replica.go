@@ -0,0 +1,264 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	pb "github.com/TFMV/slatedb_demo/proto/v2"
+	"github.com/go-kit/log/level"
+	"github.com/slatedb/slatedb-go/slatedb"
+	"github.com/thanos-io/objstore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// A read-only replica serves reads from the bucket of a primary server
+// without writing to it. slatedb-go has no reader mode: opening a DB bumps
+// the writer epoch in a new manifest, which would fence the primary, and
+// flushes the replayed WAL to L0. The replica therefore opens the DB on an
+// overlayBucket that keeps those writes in memory.
+//
+// A DB opened this way follows its own manifests, not those of the primary,
+// so the replica opens the DB again, with a new overlay, every refresh
+// interval, along with the key index checkpointed by the primary, which it
+// brings up to date from the journal. Reads see the writes of the primary
+// once the WAL that holds them was written, and the refresh that follows.
+
+const defaultRefreshInterval = 5 * time.Second
+
+// replicaOptions configure a read-only replica.
+type replicaOptions struct {
+	refreshInterval time.Duration
+}
+
+// overlayBucket reads from a bucket and keeps the objects written through
+// it in memory, where they hide the objects of the same name.
+type overlayBucket struct {
+	objstore.Bucket
+	local *objstore.InMemBucket
+}
+
+func newOverlayBucket(bucket objstore.Bucket) *overlayBucket {
+	return &overlayBucket{Bucket: bucket, local: objstore.NewInMemBucket()}
+}
+
+// bucketFor returns the bucket that holds the object name.
+func (b *overlayBucket) bucketFor(ctx context.Context, name string) objstore.Bucket {
+	if ok, _ := b.local.Exists(ctx, name); ok {
+		return b.local
+	}
+	return b.Bucket
+}
+
+func (b *overlayBucket) Upload(ctx context.Context, name string, r io.Reader) error {
+	return b.local.Upload(ctx, name, r)
+}
+
+func (b *overlayBucket) Delete(ctx context.Context, name string) error {
+	return b.local.Delete(ctx, name)
+}
+
+func (b *overlayBucket) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	return b.bucketFor(ctx, name).Get(ctx, name)
+}
+
+func (b *overlayBucket) GetRange(ctx context.Context, name string, off, length int64) (io.ReadCloser, error) {
+	return b.bucketFor(ctx, name).GetRange(ctx, name, off, length)
+}
+
+func (b *overlayBucket) Exists(ctx context.Context, name string) (bool, error) {
+	return b.bucketFor(ctx, name).Exists(ctx, name)
+}
+
+func (b *overlayBucket) Attributes(ctx context.Context, name string) (objstore.ObjectAttributes, error) {
+	return b.bucketFor(ctx, name).Attributes(ctx, name)
+}
+
+func (b *overlayBucket) IsObjNotFoundErr(err error) bool {
+	return b.Bucket.IsObjNotFoundErr(err) || b.local.IsObjNotFoundErr(err)
+}
+
+// Iter lists the objects of both buckets. Once a manifest was written
+// through the overlay, the manifests of the underlying bucket are left out,
+// so that the DB keeps reading its own.
+func (b *overlayBucket) Iter(ctx context.Context, dir string, f func(string) error, options ...objstore.IterOption) error {
+	var names []string
+	local := make(map[string]bool)
+	hideManifests := false
+	err := b.local.Iter(ctx, dir, func(name string) error {
+		names = append(names, name)
+		local[name] = true
+		hideManifests = hideManifests || path.Ext(name) == ".manifest"
+		return nil
+	}, options...)
+	if err != nil {
+		return err
+	}
+
+	err = b.Bucket.Iter(ctx, dir, func(name string) error {
+		if !local[name] && !(hideManifests && path.Ext(name) == ".manifest") {
+			names = append(names, name)
+		}
+		return nil
+	}, options...)
+	if err != nil {
+		return err
+	}
+
+	sort.Strings(names)
+	for _, name := range names {
+		if err := f(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close releases the objects written through the overlay. The underlying
+// bucket stays open.
+func (b *overlayBucket) Close() error {
+	return b.local.Close()
+}
+
+// openReplica opens the DB and loads the key index as a replica.
+func (s *server) openReplica(ctx context.Context) (db *slatedb.DB, index *keyIndex, err error) {
+	index = newKeyIndex()
+	pos, err := s.loadIndex(ctx, index)
+	if err != nil {
+		return nil, nil, err
+	}
+	last, err := s.writerEpoch(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// slatedb-go panics on some states that a replica can observe while the
+	// primary is writing, such as a WAL object that is not complete yet.
+	defer func() {
+		if r := recover(); r != nil {
+			db, index, err = nil, nil, fmt.Errorf("failed to open database: %v", r)
+		}
+	}()
+
+	// The replica must not compact: the primary owns the compacted runs.
+	opts := slatedb.DefaultDBOptions()
+	opts.CompactorOptions = nil
+	db, err = slatedb.OpenWithOptions(s.dbPath, newOverlayBucket(s.bucket), opts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open database: %v", err)
+	}
+
+	// The replica leaves the journal to the primary, whose entries are
+	// those of the latest writer epoch.
+	if _, err := replayJournal(db, index, pos, last); err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+
+	// A Write that the primary is applying, or that a crash interrupted,
+	// is seen in full.
+	w, found, err := readWriteIntent(db)
+	if err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+	if found {
+		w.applyTo(db, index)
+	}
+	return db, index, nil
+}
+
+// refresh opens the DB and the key index again and swaps them in.
+func (s *server) refresh(ctx context.Context) error {
+	db, index, err := s.openReplica(ctx)
+	if err != nil {
+		return err
+	}
+	created, err := readNamespaces(db)
+	if err != nil {
+		db.Close()
+		return err
+	}
+
+	s.commitMu.Lock()
+	old := s.db
+	s.db = db
+	s.index.replace(index)
+	s.namespaces.replace(created)
+	s.commitMu.Unlock()
+
+	// The lease tells which server leads, for GetStats.
+	if _, _, err := s.leader.readLease(ctx); err != nil {
+		level.Debug(s.logger).Log("msg", "failed to read the leader lease", "err", err)
+	}
+	return old.Close()
+}
+
+func (s *server) refreshLoop() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.replica.refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			start := time.Now()
+			if err := s.refresh(context.Background()); err != nil {
+				level.Warn(s.logger).Log("msg", "replica refresh failed", "err", err)
+				continue
+			}
+			keys, _ := s.index.stats()
+			level.Debug(s.logger).Log("msg", "refreshed replica", "keys", keys, "took", time.Since(start))
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// readOnly returns the FAILED_PRECONDITION error of a request that a
+// replica cannot serve.
+func readOnly(method, format string, args ...interface{}) error {
+	return failure(codes.FailedPrecondition, reasonReadOnly, map[string]string{"method": method},
+		fmt.Sprintf(format, args...))
+}
+
+// rejectWrites rejects the requests that write, or administer namespaces,
+// as well as snapshots: the keys of a replica change with every refresh,
+// not with writes, so it cannot preserve their state.
+func (s *server) rejectWrites(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (interface{}, error) {
+	switch req.(type) {
+	case *pb.CreateSnapshotRequest, *pb.ReleaseSnapshotRequest:
+		return nil, readOnly(info.FullMethod, "this server is a read-only replica, snapshots are served by the primary")
+	}
+
+	spans, _ := accessOf(req)
+	for _, span := range spans {
+		if span.op == opWrite || span.op == opAdmin {
+			return nil, readOnly(info.FullMethod, "this server is a read-only replica, send %s to the primary",
+				methodName(info.FullMethod))
+		}
+	}
+	return handler(ctx, req)
+}
+
+// rejectWatches rejects watches: a replica does not see individual writes.
+func (s *server) rejectWatches(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler) error {
+	if info.FullMethod == pb.SlateDB_Watch_FullMethodName {
+		return readOnly(info.FullMethod, "this server is a read-only replica, watch the primary")
+	}
+	return handler(srv, ss)
+}
+
+// methodName returns the method of a full gRPC method name, such as Put
+// for /slatedb.v2.SlateDB/Put.
+func methodName(fullMethod string) string {
+	return fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+}
@@ -0,0 +1,1092 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	pb "github.com/TFMV/slatedb_demo/proto/v2"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/slatedb/slatedb-go/slatedb"
+	"github.com/slatedb/slatedb-go/slatedb/common"
+	"github.com/thanos-io/objstore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// checkpointInterval is how often a changed key index is written to the bucket.
+	checkpointInterval = 10 * time.Second
+
+	// maxChunkEntries and maxChunkBytes bound the size of a streamed ScanChunk.
+	maxChunkEntries = 256
+	maxChunkBytes   = 1 << 20
+
+	// deleteBatchSize is the number of keys a range delete deletes at a
+	// time while holding commitMu.
+	deleteBatchSize = 1000
+)
+
+// server implements the slatedb.v2 API on top of a slatedb-go DB.
+type server struct {
+	pb.UnimplementedSlateDBServer
+
+	logger log.Logger
+	db     *slatedb.DB
+	bucket objstore.Bucket
+	dbPath string
+	index  *keyIndex
+
+	// manifests is the latest manifest that writerEpoch read.
+	manifests manifestCursor
+
+	// journal records the keys written, on the primary only.
+	journal *journal
+
+	// replica is set on read-only replicas, and nil on the primary.
+	replica *replicaOptions
+	leader  *leadership
+
+	namespaces *namespaceRegistry
+	snapshots  *snapshotRegistry
+
+	// commitMu makes Write atomic to readers: Write holds it exclusively
+	// while every other operation holds it shared. Other writes also lock
+	// their key in keyLocks, after commitMu.
+	commitMu sync.RWMutex
+	keyLocks *keyLocks
+	watches  *watchHub
+
+	ops     opCounters
+	storage storageStats
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// newServer opens the DB at dbPath in bucket and loads its key index. With
+// replica set, the server is a read-only replica of the primary that writes
+// to the DB. Otherwise it becomes the leader, fencing any other writer.
+func newServer(ctx context.Context, logger log.Logger, bucket objstore.Bucket, dbPath string,
+	replica *replicaOptions, leader *leadership) (*server, error) {
+	s := &server{
+		logger:    logger,
+		bucket:    bucket,
+		dbPath:    dbPath,
+		index:     newKeyIndex(),
+		replica:   replica,
+		leader:    leader,
+		keyLocks:  newKeyLocks(),
+		watches:   newWatchHub(),
+		snapshots: newSnapshotRegistry(),
+		done:      make(chan struct{}),
+	}
+	s.ops.startedAt = time.Now()
+
+	if replica != nil {
+		db, index, err := s.openReplica(ctx)
+		if err != nil {
+			return nil, err
+		}
+		s.db, s.index = db, index
+		if _, _, err := leader.readLease(ctx); err != nil {
+			level.Debug(logger).Log("msg", "failed to read the leader lease", "err", err)
+		}
+	} else {
+		db, err := slatedb.Open(dbPath, bucket)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open database: %v", err)
+		}
+		s.db = db
+
+		// Opening the DB fenced the previous writer: load the latest index
+		// checkpoint and bring it up to date with the keys that every
+		// writer since journaled. A checkpoint the previous writer is still
+		// saving lands under the name of its own epoch, and is ignored
+		// once this writer saved one.
+		epoch, err := s.writerEpoch(ctx)
+		var pos journalPosition
+		if err == nil {
+			pos, err = s.loadIndex(ctx, s.index)
+		}
+		if err == nil {
+			s.journal = newJournal(epoch)
+			s.journal.replayed, err = replayJournal(db, s.index, pos, epoch-1)
+		}
+		if err == nil {
+			err = s.redoWrite()
+		}
+		if err == nil {
+			err = leader.lead(ctx, epoch)
+		}
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+		level.Info(logger).Log("msg", "leading", "instance_id", leader.id, "writer_epoch", epoch,
+			"replayed_journal", len(s.journal.replayed))
+	}
+
+	if err := s.loadNamespaces(); err != nil {
+		s.db.Close()
+		return nil, err
+	}
+
+	// A replica neither checkpoints the index nor deletes expired keys: the
+	// primary does.
+	if replica != nil {
+		s.wg.Add(2)
+		go s.refreshLoop()
+		go s.storageStatsLoop()
+		return s, nil
+	}
+
+	s.wg.Add(5)
+	go s.leaderLoop()
+	go s.checkpointLoop()
+	go s.reaperLoop()
+	go s.storageStatsLoop()
+	go s.snapshotLoop()
+
+	return s, nil
+}
+
+// Close writes a final index checkpoint, flushes and closes the DB and
+// releases the leader lease. A fenced server writes nothing: the DB belongs
+// to the new leader.
+func (s *server) Close() error {
+	close(s.done)
+	s.wg.Wait()
+
+	if s.leader.currentState() == stateFenced {
+		return nil
+	}
+	if s.replica != nil {
+		if err := s.db.Close(); err != nil {
+			return fmt.Errorf("failed to close database: %v", err)
+		}
+		return nil
+	}
+
+	ctx := context.Background()
+	err := s.checkpoint(ctx)
+	if cerr := s.db.Close(); cerr != nil {
+		return fmt.Errorf("failed to close database: %v", cerr)
+	}
+	if err != nil {
+		return err
+	}
+	return s.leader.release(ctx)
+}
+
+// checkpoint saves the key index, if it changed, along with the journal
+// position it is up to date with, and then deletes the journal entries the
+// checkpoint covers. Writes go on meanwhile: the copy of the index holds
+// every write journaled before the position, and maybe some after it, whose
+// entries are replayed again after a crash.
+func (s *server) checkpoint(ctx context.Context) error {
+	pos := s.journal.position()
+	cp, changed := s.index.checkpoint()
+	if !changed {
+		return nil
+	}
+	cp.Journal = pos
+
+	// The checkpoint must not hold writes that a crash can lose: the sync
+	// is made after every write in the copy, so they are durable once it is.
+	if err := s.journal.sync(ctx, s.db, pos); err != nil {
+		s.index.markDirty()
+		return err
+	}
+
+	// A newer writer replays the journal from the checkpoint it loaded:
+	// neither overwrite that checkpoint nor delete the entries it needs.
+	if err := s.checkEpoch(ctx); err != nil {
+		s.index.markDirty()
+		return err
+	}
+	name := indexCheckpointName(s.dbPath, s.journal.epoch)
+	if err := s.index.save(ctx, s.bucket, name, cp); err != nil {
+		return err
+	}
+	if err := s.checkEpoch(ctx); err != nil {
+		return err
+	}
+	s.journal.trim(s.db, cp.Journal)
+	s.pruneIndexes(ctx, name)
+	return nil
+}
+
+// loadIndex loads the latest key index checkpoint into index, and returns
+// the journal position it is up to date with.
+func (s *server) loadIndex(ctx context.Context, index *keyIndex) (journalPosition, error) {
+	names, err := listIndexCheckpoints(ctx, s.bucket, s.dbPath)
+	if err != nil || len(names) == 0 {
+		return journalPosition{}, err
+	}
+	return index.load(ctx, s.bucket, names[len(names)-1])
+}
+
+// pruneIndexes deletes the key index checkpoints of the writer epochs
+// before the checkpoint saved as latest.
+func (s *server) pruneIndexes(ctx context.Context, latest string) {
+	names, err := listIndexCheckpoints(ctx, s.bucket, s.dbPath)
+	if err != nil {
+		level.Warn(s.logger).Log("msg", "failed to delete old index checkpoints", "err", err)
+		return
+	}
+	for _, name := range names {
+		if name == latest {
+			break
+		}
+		if err := s.bucket.Delete(ctx, name); err != nil && !s.bucket.IsObjNotFoundErr(err) {
+			level.Warn(s.logger).Log("msg", "failed to delete old index checkpoint", "name", name, "err", err)
+		}
+	}
+}
+
+func (s *server) checkpointLoop() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(checkpointInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if !s.leader.writable() {
+				continue
+			}
+			if err := s.checkpoint(context.Background()); err != nil {
+				level.Warn(s.logger).Log("msg", "index checkpoint failed", "err", err)
+			}
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// Basic operations
+func (s *server) Put(ctx context.Context, req *pb.PutRequest) (*pb.PutResponse, error) {
+	expireAt, err := expiryOf(req.TtlSeconds, req.ExpireAt)
+	if err != nil {
+		return nil, err
+	}
+
+	s.commitMu.RLock()
+	defer s.commitMu.RUnlock()
+
+	ns, err := s.namespace(req.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if err := ns.checkKey(req.Key); err != nil {
+		return nil, err
+	}
+	key := ns.key(req.Key)
+	defer s.keyLocks.lock(key)()
+
+	s.put(key, req.Value, expireAt, slatedb.DefaultWriteOptions())
+
+	return &pb.PutResponse{
+		Message: fmt.Sprintf("Key '%s' stored successfully", displayKey(req.Key)),
+	}, nil
+}
+
+func (s *server) Get(ctx context.Context, req *pb.GetRequest) (*pb.GetResponse, error) {
+	ns, err := s.namespace(req.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if err := ns.checkKey(req.Key); err != nil {
+		return nil, err
+	}
+	key := ns.key(req.Key)
+	snap, err := s.snapshot(req.SnapshotId)
+	if err != nil {
+		return nil, err
+	}
+
+	s.commitMu.RLock()
+	defer s.commitMu.RUnlock()
+
+	s.ops.gets.Add(1)
+	value, expireAt, found, err := s.read(snap, key)
+	if err == nil && !found {
+		return nil, keyNotFound(ns, req.Key)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get key '%s': %v", displayKey(req.Key), err)
+	}
+
+	return &pb.GetResponse{
+		Value:    value,
+		Message:  fmt.Sprintf("Key '%s' retrieved successfully", displayKey(req.Key)),
+		ExpireAt: unixSeconds(expireAt),
+	}, nil
+}
+
+func (s *server) Delete(ctx context.Context, req *pb.DeleteRequest) (*pb.DeleteResponse, error) {
+	s.commitMu.RLock()
+	defer s.commitMu.RUnlock()
+
+	ns, err := s.namespace(req.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if err := ns.checkKey(req.Key); err != nil {
+		return nil, err
+	}
+	key := ns.key(req.Key)
+	defer s.keyLocks.lock(key)()
+
+	s.delete(key, slatedb.DefaultWriteOptions())
+
+	return &pb.DeleteResponse{
+		Message: fmt.Sprintf("Key '%s' deleted successfully", displayKey(req.Key)),
+	}, nil
+}
+
+// Ttl reports when key expires.
+func (s *server) Ttl(ctx context.Context, req *pb.TtlRequest) (*pb.TtlResponse, error) {
+	ns, err := s.namespace(req.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if err := ns.checkKey(req.Key); err != nil {
+		return nil, err
+	}
+	key := ns.key(req.Key)
+
+	s.commitMu.RLock()
+	defer s.commitMu.RUnlock()
+
+	_, expireAt, err := readValue(s.db, key, slatedb.DefaultReadOptions())
+	if errors.Is(err, common.ErrKeyNotFound) || expired(expireAt, time.Now().UnixNano()) {
+		return &pb.TtlResponse{
+			Message: fmt.Sprintf("Key '%s' not found", displayKey(req.Key)),
+		}, nil
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get key '%s': %v", displayKey(req.Key), err)
+	}
+
+	if expireAt == 0 {
+		return &pb.TtlResponse{
+			Found:   true,
+			Message: fmt.Sprintf("Key '%s' does not expire", displayKey(req.Key)),
+		}, nil
+	}
+
+	remaining := time.Until(time.Unix(0, expireAt))
+	ttl := int64((remaining + time.Second - 1) / time.Second)
+	return &pb.TtlResponse{
+		Found:      true,
+		TtlSeconds: ttl,
+		ExpireAt:   unixSeconds(expireAt),
+		Message:    fmt.Sprintf("Key '%s' expires in %ds", displayKey(req.Key), ttl),
+	}, nil
+}
+
+// Batch operations
+
+// BatchPut writes every entry without waiting for the WAL, except the last
+// one. WAL flushes are ordered, so once the last write is durable all
+// earlier writes are too.
+func (s *server) BatchPut(ctx context.Context, req *pb.BatchPutRequest) (*pb.BatchPutResponse, error) {
+	s.commitMu.RLock()
+	defer s.commitMu.RUnlock()
+
+	ns, err := s.namespace(req.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*pb.KeyValue, 0, len(req.Entries))
+	expiries := make([]int64, 0, len(req.Entries))
+	for _, entry := range req.Entries {
+		expireAt, err := expiryOf(entry.TtlSeconds, entry.ExpireAt)
+		if ns.checkKey(entry.Key) == nil && err == nil {
+			entries = append(entries, entry)
+			expiries = append(expiries, expireAt)
+		}
+	}
+
+	for i, entry := range entries {
+		opts := slatedb.WriteOptions{AwaitFlush: i == len(entries)-1}
+		key := ns.key(entry.Key)
+		unlock := s.keyLocks.lock(key)
+		s.put(key, entry.Value, expiries[i], opts)
+		unlock()
+	}
+
+	return &pb.BatchPutResponse{
+		Message:      fmt.Sprintf("Stored %d of %d entries", len(entries), len(req.Entries)),
+		SuccessCount: int32(len(entries)),
+		FailureCount: int32(len(req.Entries) - len(entries)),
+	}, nil
+}
+
+func (s *server) BatchGet(ctx context.Context, req *pb.BatchGetRequest) (*pb.BatchGetResponse, error) {
+	ns, err := s.namespace(req.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	snap, err := s.snapshot(req.SnapshotId)
+	if err != nil {
+		return nil, err
+	}
+
+	s.commitMu.RLock()
+	defer s.commitMu.RUnlock()
+
+	resp := &pb.BatchGetResponse{}
+	for _, key := range req.Keys {
+		if ns.checkKey(key) != nil {
+			resp.MissingKeys = append(resp.MissingKeys, key)
+			continue
+		}
+
+		s.ops.gets.Add(1)
+		value, expireAt, found, err := s.read(snap, ns.key(key))
+		if err == nil && !found {
+			resp.MissingKeys = append(resp.MissingKeys, key)
+			continue
+		}
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to get key '%s': %v", displayKey(key), err)
+		}
+		resp.Entries = append(resp.Entries, &pb.KeyValue{Key: key, Value: value, ExpireAt: unixSeconds(expireAt)})
+	}
+
+	resp.Message = fmt.Sprintf("Retrieved %d of %d keys", len(resp.Entries), len(req.Keys))
+	return resp, nil
+}
+
+// BatchDelete uses the same flushing strategy as BatchPut.
+func (s *server) BatchDelete(ctx context.Context, req *pb.BatchDeleteRequest) (*pb.BatchDeleteResponse, error) {
+	s.commitMu.RLock()
+	defer s.commitMu.RUnlock()
+
+	ns, err := s.namespace(req.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([][]byte, 0, len(req.Keys))
+	for _, key := range req.Keys {
+		if ns.checkKey(key) == nil {
+			keys = append(keys, ns.key(key))
+		}
+	}
+
+	for i, key := range keys {
+		opts := slatedb.WriteOptions{AwaitFlush: i == len(keys)-1}
+		unlock := s.keyLocks.lock(key)
+		s.delete(key, opts)
+		unlock()
+	}
+
+	return &pb.BatchDeleteResponse{
+		Message:      fmt.Sprintf("Deleted %d of %d keys", len(keys), len(req.Keys)),
+		SuccessCount: int32(len(keys)),
+		FailureCount: int32(len(req.Keys) - len(keys)),
+	}, nil
+}
+
+// Range deletes
+func (s *server) DeleteRange(ctx context.Context, req *pb.DeleteRangeRequest) (*pb.DeleteRangeResponse, error) {
+	ns, err := s.namespace(req.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	start, end, err := keyRange(ns, req.StartKey, req.EndKey, "")
+	if err != nil {
+		return nil, err
+	}
+
+	deleted, err := s.deleteRange(ctx, ns, start, end, req.DryRun)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.DeleteRangeResponse{
+		Message: fmt.Sprintf("%s %d keys in range ['%s', '%s')",
+			deleteVerb(req.DryRun), deleted, displayKey(req.StartKey), displayKey(req.EndKey)),
+		DeletedKeys: deleted,
+	}, nil
+}
+
+func (s *server) DeletePrefix(ctx context.Context, req *pb.DeletePrefixRequest) (*pb.DeletePrefixResponse, error) {
+	if len(req.Prefix) == 0 {
+		return nil, invalidArgument("prefix", "prefix cannot be empty, use DeleteRange to delete every key")
+	}
+	ns, err := s.namespace(req.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	start, end, err := prefixRange(ns, req.Prefix, "")
+	if err != nil {
+		return nil, err
+	}
+
+	deleted, err := s.deleteRange(ctx, ns, start, end, req.DryRun)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.DeletePrefixResponse{
+		Message:     fmt.Sprintf("%s %d keys with prefix '%s'", deleteVerb(req.DryRun), deleted, displayKey(req.Prefix)),
+		DeletedKeys: deleted,
+	}, nil
+}
+
+// deleteRange deletes the live keys of ns in [start, end) and returns how
+// many it deleted, or only counts them if dryRun is set. Keys are deleted in
+// batches, each under commitMu, so that a large delete does not hold up
+// transactions until it completes.
+func (s *server) deleteRange(ctx context.Context, ns namespace, start, end string, dryRun bool) (int64, error) {
+	if dryRun {
+		keys, _ := s.index.rangeStats(start, end)
+		return keys, nil
+	}
+
+	var deleted int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return deleted, status.FromContextError(err).Err()
+		}
+
+		keys, err := s.deleteBatch(ns, start, end)
+		if err != nil {
+			return deleted, err
+		}
+		deleted += int64(len(keys))
+		if len(keys) < deleteBatchSize {
+			break
+		}
+		start = keyAfter(keys[len(keys)-1])
+	}
+
+	level.Info(s.logger).Log("msg", "deleted range", "namespace", ns.name, "keys", deleted)
+	return deleted, nil
+}
+
+// deleteBatch deletes up to deleteBatchSize keys of ns in [start, end) and
+// returns them. As in BatchDelete, only the last delete waits for the WAL
+// flush.
+func (s *server) deleteBatch(ns namespace, start, end string) ([]string, error) {
+	s.commitMu.RLock()
+	defer s.commitMu.RUnlock()
+
+	// The namespace may have been dropped since the previous batch.
+	if _, err := s.namespace(ns.name); err != nil {
+		return nil, err
+	}
+
+	keys := s.index.scan(start, end, deleteBatchSize)
+	for i, key := range keys {
+		opts := slatedb.WriteOptions{AwaitFlush: i == len(keys)-1}
+		unlock := s.keyLocks.lock([]byte(key))
+		s.delete([]byte(key), opts)
+		unlock()
+	}
+	return keys, nil
+}
+
+func deleteVerb(dryRun bool) string {
+	if dryRun {
+		return "Would delete"
+	}
+	return "Deleted"
+}
+
+// Transactional operations
+
+// Write holds commitMu exclusively while it validates every operation and
+// then applies them in order. The operations are stored in an intent record
+// first, so that a crash does not persist only some of them.
+func (s *server) Write(ctx context.Context, req *pb.WriteRequest) (*pb.WriteResponse, error) {
+	s.commitMu.Lock()
+	defer s.commitMu.Unlock()
+
+	ns, err := s.namespace(req.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	intent := &writeIntent{Ops: make([]intentOp, len(req.Operations))}
+	resp := &pb.WriteResponse{}
+	for i, op := range req.Operations {
+		var key []byte
+		var field string
+		switch op := op.Op.(type) {
+		case *pb.WriteOperation_Put:
+			key = op.Put.GetKey()
+			expireAt, err := expiryOf(op.Put.GetTtlSeconds(), op.Put.GetExpireAt())
+			if err != nil {
+				return nil, invalidArgument(fmt.Sprintf("operations[%d].put.%s", i, invalidField(err)),
+					"operation %d: %s", i, status.Convert(err).Message())
+			}
+			intent.Ops[i] = intentOp{Key: ns.key(key), Value: op.Put.GetValue(), ExpireAt: expireAt}
+			field = fmt.Sprintf("operations[%d].put.key", i)
+			resp.PutCount++
+		case *pb.WriteOperation_Delete:
+			key = op.Delete
+			intent.Ops[i] = intentOp{Key: ns.key(key), Delete: true}
+			field = fmt.Sprintf("operations[%d].delete", i)
+			resp.DeleteCount++
+		default:
+			return nil, invalidArgument(fmt.Sprintf("operations[%d]", i), "operation %d has no put or delete", i)
+		}
+		if err := ns.checkKey(key); err != nil {
+			return nil, invalidArgument(field, "operation %d: %s", i, status.Convert(err).Message())
+		}
+	}
+
+	if err := intent.store(s.db); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to write: %v", err)
+	}
+	s.apply(intent)
+	clearWriteIntent(s.db)
+
+	resp.Message = fmt.Sprintf("Committed %d operations (%d puts, %d deletes)",
+		len(req.Operations), resp.PutCount, resp.DeleteCount)
+	return resp, nil
+}
+
+// Conditional operations
+func (s *server) CompareAndSwap(ctx context.Context, req *pb.CompareAndSwapRequest) (*pb.CompareAndSwapResponse, error) {
+	s.commitMu.RLock()
+	defer s.commitMu.RUnlock()
+
+	ns, err := s.namespace(req.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if err := ns.checkKey(req.Key); err != nil {
+		return nil, err
+	}
+	key := ns.key(req.Key)
+	defer s.keyLocks.lock(key)()
+
+	current, found, err := s.currentValue(key)
+	if err != nil {
+		return nil, err
+	}
+	if req.ExpectAbsent && found {
+		return nil, conditionFailed(req.Key, current, found, "key '%s' already exists", displayKey(req.Key))
+	}
+	if !req.ExpectAbsent && (!found || !bytes.Equal(current, req.ExpectedValue)) {
+		return nil, conditionFailed(req.Key, current, found, "key '%s' does not hold the expected value", displayKey(req.Key))
+	}
+
+	s.put(key, req.NewValue, 0, slatedb.DefaultWriteOptions())
+
+	return &pb.CompareAndSwapResponse{
+		Message: fmt.Sprintf("Key '%s' swapped successfully", displayKey(req.Key)),
+	}, nil
+}
+
+func (s *server) PutIfAbsent(ctx context.Context, req *pb.PutIfAbsentRequest) (*pb.PutIfAbsentResponse, error) {
+	_, err := s.CompareAndSwap(ctx, &pb.CompareAndSwapRequest{
+		Key:          req.Key,
+		ExpectAbsent: true,
+		NewValue:     req.Value,
+		Namespace:    req.Namespace,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &pb.PutIfAbsentResponse{
+		Message: fmt.Sprintf("Key '%s' stored successfully", displayKey(req.Key)),
+	}, nil
+}
+
+func (s *server) DeleteIfEquals(ctx context.Context, req *pb.DeleteIfEqualsRequest) (*pb.DeleteIfEqualsResponse, error) {
+	s.commitMu.RLock()
+	defer s.commitMu.RUnlock()
+
+	ns, err := s.namespace(req.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if err := ns.checkKey(req.Key); err != nil {
+		return nil, err
+	}
+	key := ns.key(req.Key)
+	defer s.keyLocks.lock(key)()
+
+	current, found, err := s.currentValue(key)
+	if err != nil {
+		return nil, err
+	}
+	if !found || !bytes.Equal(current, req.ExpectedValue) {
+		return nil, conditionFailed(req.Key, current, found, "key '%s' does not hold the expected value", displayKey(req.Key))
+	}
+
+	s.delete(key, slatedb.DefaultWriteOptions())
+
+	return &pb.DeleteIfEqualsResponse{
+		Message: fmt.Sprintf("Key '%s' deleted successfully", displayKey(req.Key)),
+	}, nil
+}
+
+// put writes key to the DB and the index and notifies watchers. The caller
+// holds commitMu and, unless it holds commitMu exclusively, the key lock.
+func (s *server) put(key, value []byte, expireAt int64, opts slatedb.WriteOptions) {
+	s.preserve(key)
+	seq := s.journal.record(s.db, key)
+	s.db.PutWithOptions(key, encodeValue(value, expireAt), opts)
+	s.index.put(string(key), len(value), expireAt)
+	s.journal.done(seq)
+	s.ops.puts.Add(1)
+	s.watches.publish(&pb.WatchEvent{
+		Type:     pb.WatchEvent_PUT,
+		Key:      key,
+		Value:    value,
+		ExpireAt: unixSeconds(expireAt),
+	})
+}
+
+// delete is the counterpart of put for deletes.
+func (s *server) delete(key []byte, opts slatedb.WriteOptions) {
+	s.preserve(key)
+	seq := s.journal.record(s.db, key)
+	s.db.DeleteWithOptions(key, opts)
+	s.index.delete(string(key))
+	s.journal.done(seq)
+	s.ops.deletes.Add(1)
+	s.watches.publish(&pb.WatchEvent{Type: pb.WatchEvent_DELETE, Key: key})
+}
+
+// currentValue reads the latest value of key, including writes that are not
+// yet durable: with the key locked, those writes are the current state and
+// any write made next is flushed after them.
+func (s *server) currentValue(key []byte) ([]byte, bool, error) {
+	value, expireAt, err := readValue(s.db, key, slatedb.ReadOptions{ReadLevel: slatedb.Uncommitted})
+	if errors.Is(err, common.ErrKeyNotFound) || expired(expireAt, time.Now().UnixNano()) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, status.Errorf(codes.Internal, "failed to get key '%s': %v", displayKey(key), err)
+	}
+	return value, true, nil
+}
+
+// conditionFailed returns a FAILED_PRECONDITION error carrying the current
+// value of key as a CurrentValue detail.
+func conditionFailed(key, value []byte, found bool, format string, args ...interface{}) error {
+	return failure(codes.FailedPrecondition, reasonConditionFailed, map[string]string{"key": displayKey(key)},
+		fmt.Sprintf(format, args...), &pb.CurrentValue{Key: key, Found: found, Value: value})
+}
+
+// Scanning operations
+func (s *server) PrefixScan(ctx context.Context, req *pb.PrefixScanRequest) (*pb.PrefixScanResponse, error) {
+	ns, err := s.namespace(req.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	start, end, err := prefixRange(ns, req.Prefix, req.PageToken)
+	if err != nil {
+		return nil, err
+	}
+
+	snap, err := s.snapshot(req.SnapshotId)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, next, err := s.scan(ctx, ns, snap, start, end, int(req.Limit))
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.PrefixScanResponse{
+		Entries:       entries,
+		Message:       fmt.Sprintf("Found %d keys with prefix '%s'", len(entries), displayKey(req.Prefix)),
+		NextPageToken: next,
+	}, nil
+}
+
+func (s *server) RangeScan(ctx context.Context, req *pb.RangeScanRequest) (*pb.RangeScanResponse, error) {
+	ns, err := s.namespace(req.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	start, end, err := keyRange(ns, req.StartKey, req.EndKey, req.PageToken)
+	if err != nil {
+		return nil, err
+	}
+
+	snap, err := s.snapshot(req.SnapshotId)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, next, err := s.scan(ctx, ns, snap, start, end, int(req.Limit))
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.RangeScanResponse{
+		Entries: entries,
+		Message: fmt.Sprintf("Found %d keys in range ['%s', '%s')",
+			len(entries), displayKey(req.StartKey), displayKey(req.EndKey)),
+		NextPageToken: next,
+	}, nil
+}
+
+// PrefixScanStream streams every live entry with the given prefix.
+func (s *server) PrefixScanStream(req *pb.PrefixScanRequest, stream pb.SlateDB_PrefixScanStreamServer) error {
+	ns, err := s.namespace(req.Namespace)
+	if err != nil {
+		return err
+	}
+	start, end, err := prefixRange(ns, req.Prefix, req.PageToken)
+	if err != nil {
+		return err
+	}
+	snap, err := s.snapshot(req.SnapshotId)
+	if err != nil {
+		return err
+	}
+	return s.scanStream(stream.Context(), ns, snap, start, end, int(req.Limit), stream.Send)
+}
+
+// RangeScanStream streams every live entry in [start_key, end_key).
+func (s *server) RangeScanStream(req *pb.RangeScanRequest, stream pb.SlateDB_RangeScanStreamServer) error {
+	ns, err := s.namespace(req.Namespace)
+	if err != nil {
+		return err
+	}
+	start, end, err := keyRange(ns, req.StartKey, req.EndKey, req.PageToken)
+	if err != nil {
+		return err
+	}
+	snap, err := s.snapshot(req.SnapshotId)
+	if err != nil {
+		return err
+	}
+	return s.scanStream(stream.Context(), ns, snap, start, end, int(req.Limit), stream.Send)
+}
+
+// prefixRange returns the index range scanned for prefix in ns, resuming
+// after the page token if one is given.
+func prefixRange(ns namespace, prefix []byte, token string) (string, string, error) {
+	stored := string(ns.key(prefix))
+	start, end := ns.clamp(stored, prefixEnd(stored))
+	start, err := resumeKey(token, start, end)
+	return start, end, err
+}
+
+// keyRange returns the index range scanned for [startKey, endKey) in ns,
+// resuming after the page token if one is given.
+func keyRange(ns namespace, startKey, endKey []byte, token string) (string, string, error) {
+	if len(endKey) > 0 && bytes.Compare(startKey, endKey) > 0 {
+		return "", "", invalidArgument("start_key",
+			"start key '%s' is after end key '%s'", displayKey(startKey), displayKey(endKey))
+	}
+
+	end := ""
+	if len(endKey) > 0 {
+		end = string(ns.key(endKey))
+	}
+	start, end := ns.clamp(string(ns.key(startKey)), end)
+	start, err := resumeKey(token, start, end)
+	return start, end, err
+}
+
+// scan reads up to limit live entries in [start, end) of ns, as of snap if
+// it is not nil. If there are more keys in the range it also returns the
+// token of the next page.
+func (s *server) scan(ctx context.Context, ns namespace, snap *snapshot, start, end string, limit int) ([]*pb.KeyValue, string, error) {
+	s.ops.scans.Add(1)
+
+	fetch := limit
+	if limit > 0 {
+		fetch++
+	}
+
+	keys := s.listKeys(snap, start, end, fetch)
+	next := ""
+	if limit > 0 && len(keys) > limit {
+		keys = keys[:limit]
+		next = pageToken(keys[limit-1])
+	}
+
+	s.commitMu.RLock()
+	defer s.commitMu.RUnlock()
+
+	entries, err := s.readEntries(ctx, ns, snap, keys)
+	if err != nil {
+		return nil, "", err
+	}
+	return entries, next, nil
+}
+
+// readEntries reads the values of the stored keys of ns, skipping keys
+// deleted since they were listed by the index.
+func (s *server) readEntries(ctx context.Context, ns namespace, snap *snapshot, keys []string) ([]*pb.KeyValue, error) {
+	var entries []*pb.KeyValue
+	for _, key := range keys {
+		if err := ctx.Err(); err != nil {
+			return nil, status.FromContextError(err).Err()
+		}
+
+		entry, err := s.readEntry(ns, snap, key)
+		if err != nil {
+			return nil, err
+		}
+		if entry != nil {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// scanStream sends up to limit live entries in [start, end) in chunks of at
+// most maxChunkEntries entries or maxChunkBytes bytes. send blocks while the
+// client's flow control window is full, and the scan stops as soon as the
+// client cancels the stream.
+func (s *server) scanStream(ctx context.Context, ns namespace, snap *snapshot, start, end string, limit int, send func(*pb.ScanChunk) error) error {
+	s.ops.scans.Add(1)
+
+	chunk := &pb.ScanChunk{}
+	chunkBytes, sent := 0, 0
+
+	for {
+		// Each group of keys renews the lease of the snapshot, and fails
+		// if the snapshot was released meanwhile.
+		if snap != nil {
+			if _, err := s.snapshot(snap.id); err != nil {
+				return err
+			}
+		}
+
+		keys := s.listKeys(snap, start, end, maxChunkEntries)
+		if len(keys) == 0 {
+			break
+		}
+		if limit > 0 && len(keys) > limit-sent {
+			keys = keys[:limit-sent]
+		}
+
+		// Each group of keys is read under commitMu so that it never
+		// observes a partially applied Write. The lock is released before
+		// sending, so a slow client cannot hold up writers.
+		s.commitMu.RLock()
+		entries, err := s.readEntries(ctx, ns, snap, keys)
+		s.commitMu.RUnlock()
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			chunk.Entries = append(chunk.Entries, entry)
+			chunkBytes += len(entry.Key) + len(entry.Value)
+			sent++
+
+			if len(chunk.Entries) == maxChunkEntries || chunkBytes >= maxChunkBytes {
+				if err := send(chunk); err != nil {
+					return err
+				}
+				chunk = &pb.ScanChunk{}
+				chunkBytes = 0
+			}
+		}
+
+		if limit > 0 && sent == limit {
+			break
+		}
+		start = keyAfter(keys[len(keys)-1])
+	}
+
+	if len(chunk.Entries) > 0 {
+		return send(chunk)
+	}
+	return nil
+}
+
+// readEntry reads the stored key of ns as of snap, or its latest value if
+// snap is nil. It returns nil if the key was deleted or expired after it was
+// listed.
+func (s *server) readEntry(ns namespace, snap *snapshot, key string) (*pb.KeyValue, error) {
+	value, expireAt, found, err := s.read(snap, []byte(key))
+	if err == nil && !found {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to read key '%s': %v", displayKey(ns.external(key)), err)
+	}
+	return &pb.KeyValue{Key: ns.external(key), Value: value, ExpireAt: unixSeconds(expireAt)}, nil
+}
+
+// Statistics and monitoring
+//
+// The totals of the default namespace cover the whole database, while those
+// of another namespace cover that namespace only. The counts come from the
+// running totals of the key index, and include expired keys until the reaper
+// deletes them.
+func (s *server) GetStats(ctx context.Context, req *pb.GetStatsRequest) (*pb.GetStatsResponse, error) {
+	ns, err := s.namespace(req.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, size := s.index.stats()
+	if ns != defaultNamespace {
+		keys, size = s.namespaceStats(ns)
+	}
+	resp := &pb.GetStatsResponse{
+		TotalKeys:      keys,
+		TotalSizeBytes: size,
+		DbPath:         s.dbPath,
+		Message:        "Statistics retrieved successfully",
+		Storage:        s.storage.get(),
+		Ops:            s.ops.proto(),
+		Leader:         s.leader.proto(),
+	}
+
+	for _, prefix := range req.Prefixes {
+		keys, size := s.prefixStats(ns, prefix)
+		resp.Prefixes = append(resp.Prefixes, &pb.PrefixStats{
+			Prefix:    prefix,
+			Keys:      keys,
+			SizeBytes: size,
+		})
+	}
+
+	if ns != defaultNamespace {
+		resp.Namespaces = append(resp.Namespaces, s.namespaceStatsProto(ns))
+		return resp, nil
+	}
+	resp.Namespaces = append(resp.Namespaces, s.namespaceStatsProto(defaultNamespace))
+	list, err := s.ListNamespaces(ctx, &pb.ListNamespacesRequest{})
+	if err != nil {
+		return nil, err
+	}
+	resp.Namespaces = append(resp.Namespaces, list.Namespaces...)
+	return resp, nil
+}
+
+// displayKey renders key for messages. Keys that are not printable UTF-8
+// are shown in hex, since messages must be valid UTF-8 strings.
+func displayKey(key []byte) string {
+	if utf8.Valid(key) && strings.IndexFunc(string(key), isNonPrintable) < 0 {
+		return string(key)
+	}
+	return "0x" + hex.EncodeToString(key)
+}
+
+func isNonPrintable(r rune) bool {
+	return !unicode.IsPrint(r)
+}
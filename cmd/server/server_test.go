@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/TFMV/slatedb_demo/proto/v2"
+	"github.com/go-kit/log"
+	"github.com/thanos-io/objstore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// testDBPath is the path of the DB of test servers in their bucket.
+const testDBPath = "db"
+
+// openTestServer opens a primary server on the DB in bucket.
+func openTestServer(t *testing.T, bucket objstore.Bucket) *server {
+	t.Helper()
+	leader := newLeadership(bucket, testDBPath, defaultLeaseTTL, stateStandby)
+	s, err := newServer(context.Background(), log.NewNopLogger(), bucket, testDBPath, nil, leader)
+	if err != nil {
+		t.Fatalf("failed to open server: %v", err)
+	}
+	return s
+}
+
+// crash stops s as a crash would once its writes reached the WAL: the DB
+// is closed, but the key index is not checkpointed and the lease is kept.
+func crash(t *testing.T, s *server) {
+	t.Helper()
+	close(s.done)
+	s.wg.Wait()
+	if err := s.db.Close(); err != nil {
+		t.Fatalf("failed to close database: %v", err)
+	}
+}
+
+func putOp(key, value string) *pb.WriteOperation {
+	return &pb.WriteOperation{Op: &pb.WriteOperation_Put{Put: &pb.KeyValue{Key: []byte(key), Value: []byte(value)}}}
+}
+
+func deleteOp(key string) *pb.WriteOperation {
+	return &pb.WriteOperation{Op: &pb.WriteOperation_Delete{Delete: []byte(key)}}
+}
+
+func mustPut(t *testing.T, s *server, key, value string) {
+	t.Helper()
+	if _, err := s.Put(context.Background(), &pb.PutRequest{Key: []byte(key), Value: []byte(value)}); err != nil {
+		t.Fatalf("Put(%q) failed: %v", key, err)
+	}
+}
+
+// wantValue checks the value of key read with Get, or that key does not
+// exist if value is nil.
+func wantValue(t *testing.T, s *server, key string, value []byte) {
+	t.Helper()
+	resp, err := s.Get(context.Background(), &pb.GetRequest{Key: []byte(key)})
+	switch {
+	case value == nil && status.Code(err) == codes.NotFound:
+	case value == nil && err == nil:
+		t.Errorf("Get(%q) = %q, want NOT_FOUND", key, resp.Value)
+	case err != nil:
+		t.Errorf("Get(%q) failed: %v", key, err)
+	case string(resp.Value) != string(value):
+		t.Errorf("Get(%q) = %q, want %q", key, resp.Value, value)
+	}
+}
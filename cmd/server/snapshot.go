@@ -0,0 +1,365 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	pb "github.com/TFMV/slatedb_demo/proto/v2"
+	"github.com/go-kit/log/level"
+	"github.com/slatedb/slatedb-go/slatedb"
+	"github.com/slatedb/slatedb-go/slatedb/common"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Snapshots are copy-on-write, as slatedb-go cannot read older versions of
+// a key: before a key is written, its current state is saved in every open
+// snapshot that does not hold it yet. A read from a snapshot returns the
+// saved state of a key if there is one, and otherwise the latest state,
+// which has not changed since the snapshot was created. Snapshots are
+// created while holding commitMu exclusively, so that no write is half done
+// at that time.
+
+const (
+	defaultSnapshotTTL = time.Minute
+	maxSnapshotTTL     = time.Hour
+
+	// maxSnapshots bounds the number of snapshots each principal can have
+	// open, as each of them adds to the work of writes.
+	maxSnapshots = 64
+
+	// snapshotSweepInterval is how often snapshots whose lease ran out are
+	// discarded.
+	snapshotSweepInterval = time.Second
+)
+
+// savedKey is the state of a key when a snapshot was created.
+type savedKey struct {
+	value    []byte
+	expireAt int64 // Unix nanoseconds, or 0 if the key does not expire
+	found    bool
+}
+
+// liveAt reports whether the key existed and had not expired at now.
+func (k savedKey) liveAt(now int64) bool {
+	return k.found && (k.expireAt == 0 || k.expireAt > now)
+}
+
+type snapshot struct {
+	id        string
+	owner     string // the principal that created it
+	createdAt int64  // Unix nanoseconds
+	ttl       time.Duration
+
+	mu    sync.Mutex
+	saved map[string]savedKey
+}
+
+func (snap *snapshot) lookup(key string) (savedKey, bool) {
+	snap.mu.Lock()
+	defer snap.mu.Unlock()
+	saved, ok := snap.saved[key]
+	return saved, ok
+}
+
+// save records the state of key unless an earlier state is recorded.
+func (snap *snapshot) save(key string, state savedKey) {
+	snap.mu.Lock()
+	defer snap.mu.Unlock()
+	if _, ok := snap.saved[key]; !ok {
+		snap.saved[key] = state
+	}
+}
+
+// savedIn returns the saved keys in [start, end) that existed when the
+// snapshot was created, and whether each was saved at all.
+func (snap *snapshot) savedIn(start, end string) ([]string, map[string]bool) {
+	snap.mu.Lock()
+	defer snap.mu.Unlock()
+
+	var live []string
+	saved := make(map[string]bool)
+	for key, state := range snap.saved {
+		if key < start || (end != "" && key >= end) {
+			continue
+		}
+		saved[key] = true
+		if state.liveAt(snap.createdAt) {
+			live = append(live, key)
+		}
+	}
+	return live, saved
+}
+
+// snapshotRegistry holds the open snapshots and their leases.
+type snapshotRegistry struct {
+	mu        sync.Mutex
+	snapshots map[string]*snapshot
+	leases    map[string]time.Time
+}
+
+func newSnapshotRegistry() *snapshotRegistry {
+	return &snapshotRegistry{
+		snapshots: make(map[string]*snapshot),
+		leases:    make(map[string]time.Time),
+	}
+}
+
+// get returns the open snapshot id and renews its lease.
+func (r *snapshotRegistry) get(id string) (*snapshot, time.Time, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	snap, ok := r.snapshots[id]
+	if !ok {
+		return nil, time.Time{}, false
+	}
+	r.leases[id] = time.Now().Add(snap.ttl)
+	return snap, r.leases[id], true
+}
+
+// needing returns the open snapshots that hold no state of key yet.
+func (r *snapshotRegistry) needing(key string) []*snapshot {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var needing []*snapshot
+	for _, snap := range r.snapshots {
+		if _, ok := snap.lookup(key); !ok {
+			needing = append(needing, snap)
+		}
+	}
+	return needing
+}
+
+// owned returns the number of open snapshots that owner created. The
+// caller holds r.mu.
+func (r *snapshotRegistry) owned(owner string) int {
+	n := 0
+	for _, snap := range r.snapshots {
+		if snap.owner == owner {
+			n++
+		}
+	}
+	return n
+}
+
+// release discards the snapshot id if owner created it. It reports whether
+// the snapshot is open, and whether it was released.
+func (r *snapshotRegistry) release(id, owner string) (open, released bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	snap, ok := r.snapshots[id]
+	if !ok {
+		return false, false
+	}
+	if snap.owner != owner {
+		return true, false
+	}
+	delete(r.snapshots, id)
+	delete(r.leases, id)
+	return true, true
+}
+
+// expire discards the snapshots whose lease ran out before now and returns
+// their IDs.
+func (r *snapshotRegistry) expire(now time.Time) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var expired []string
+	for id, lease := range r.leases {
+		if lease.Before(now) {
+			delete(r.snapshots, id)
+			delete(r.leases, id)
+			expired = append(expired, id)
+		}
+	}
+	return expired
+}
+
+// snapshot returns the open snapshot id, renewing its lease, or nil if id
+// is empty.
+func (s *server) snapshot(id string) (*snapshot, error) {
+	if id == "" {
+		return nil, nil
+	}
+	snap, _, ok := s.snapshots.get(id)
+	if !ok {
+		return nil, failure(codes.NotFound, reasonSnapshotNotFound, map[string]string{"snapshot_id": id},
+			fmt.Sprintf("snapshot '%s' does not exist or has expired", id))
+	}
+	return snap, nil
+}
+
+// preserve saves the current state of the stored key in the open snapshots
+// before it is written. The caller holds commitMu and the key lock, or
+// commitMu exclusively.
+func (s *server) preserve(key []byte) {
+	needing := s.snapshots.needing(string(key))
+	if len(needing) == 0 {
+		return
+	}
+
+	value, expireAt, err := readValue(s.db, key, slatedb.ReadOptions{ReadLevel: slatedb.Uncommitted})
+	if err != nil && !errors.Is(err, common.ErrKeyNotFound) {
+		level.Warn(s.logger).Log("msg", "failed to preserve key for snapshots", "key", displayKey(key), "err", err)
+	}
+	state := savedKey{value: value, expireAt: expireAt, found: err == nil}
+	for _, snap := range needing {
+		snap.save(string(key), state)
+	}
+}
+
+// read returns the value and expiry of the stored key as seen by snap, or
+// the latest ones if snap is nil, and whether the key exists.
+func (s *server) read(snap *snapshot, key []byte) ([]byte, int64, bool, error) {
+	value, expireAt, err := readValue(s.db, key, slatedb.DefaultReadOptions())
+
+	now := time.Now().UnixNano()
+	if snap != nil {
+		// A write that raced with this read saved the state it replaced
+		// before writing, so checking after the read cannot miss it.
+		if saved, ok := snap.lookup(string(key)); ok {
+			return saved.value, saved.expireAt, saved.liveAt(snap.createdAt), nil
+		}
+		now = snap.createdAt
+	}
+
+	if errors.Is(err, common.ErrKeyNotFound) || expired(expireAt, now) {
+		return nil, 0, false, nil
+	}
+	if err != nil {
+		return nil, 0, false, err
+	}
+	return value, expireAt, true, nil
+}
+
+// listKeys returns up to limit stored keys in [start, end) that exist in
+// snap, or that exist now if snap is nil, in ascending order.
+func (s *server) listKeys(snap *snapshot, start, end string, limit int) []string {
+	if snap == nil {
+		return s.index.scan(start, end, limit)
+	}
+
+	var keys []string
+	for {
+		// The index lists the keys as they are now. The saved keys replace
+		// those that were written since the snapshot was created.
+		indexed := s.index.scanAt(start, end, limit, snap.createdAt)
+		upper, done := end, limit <= 0 || len(indexed) < limit
+		if !done {
+			upper = keyAfter(indexed[len(indexed)-1])
+		}
+
+		live, saved := snap.savedIn(start, upper)
+		for _, key := range indexed {
+			if !saved[key] {
+				keys = append(keys, key)
+			}
+		}
+		keys = append(keys, live...)
+
+		if done || len(keys) >= limit {
+			break
+		}
+		start = upper
+	}
+
+	sort.Strings(keys)
+	if limit > 0 && len(keys) > limit {
+		keys = keys[:limit]
+	}
+	return keys
+}
+
+func (s *server) CreateSnapshot(ctx context.Context, req *pb.CreateSnapshotRequest) (*pb.CreateSnapshotResponse, error) {
+	// Check the seconds before converting them, which could overflow.
+	if req.TtlSeconds < 0 || req.TtlSeconds > int64(maxSnapshotTTL/time.Second) {
+		return nil, invalidArgument("ttl_seconds", "ttl_seconds must be between 0 and %d", int(maxSnapshotTTL.Seconds()))
+	}
+	ttl := time.Duration(req.TtlSeconds) * time.Second
+	if ttl == 0 {
+		ttl = defaultSnapshotTTL
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create snapshot ID: %v", err)
+	}
+
+	// No write is in progress while commitMu is held exclusively, so the
+	// snapshot starts from a state that every write has either completed
+	// or not started.
+	s.commitMu.Lock()
+	defer s.commitMu.Unlock()
+
+	owner := principalOf(ctx)
+	s.snapshots.mu.Lock()
+	if s.snapshots.owned(owner) >= maxSnapshots {
+		s.snapshots.mu.Unlock()
+		return nil, failure(codes.ResourceExhausted, reasonTooManySnapshots, nil,
+			fmt.Sprintf("%d snapshots are open, release one first", maxSnapshots))
+	}
+	snap := &snapshot{
+		id:        hex.EncodeToString(id),
+		owner:     owner,
+		createdAt: time.Now().UnixNano(),
+		ttl:       ttl,
+		saved:     make(map[string]savedKey),
+	}
+	lease := time.Now().Add(ttl)
+	s.snapshots.snapshots[snap.id] = snap
+	s.snapshots.leases[snap.id] = lease
+	s.snapshots.mu.Unlock()
+
+	level.Debug(s.logger).Log("msg", "created snapshot", "snapshot", snap.id, "owner", owner, "ttl", ttl)
+	return &pb.CreateSnapshotResponse{
+		SnapshotId: snap.id,
+		ExpireAt:   lease.Unix(),
+		Message:    fmt.Sprintf("Snapshot '%s' created successfully", snap.id),
+	}, nil
+}
+
+func (s *server) ReleaseSnapshot(ctx context.Context, req *pb.ReleaseSnapshotRequest) (*pb.ReleaseSnapshotResponse, error) {
+	principal := principalOf(ctx)
+	open, released := s.snapshots.release(req.SnapshotId, principal)
+	if !open {
+		return nil, failure(codes.NotFound, reasonSnapshotNotFound, map[string]string{"snapshot_id": req.SnapshotId},
+			fmt.Sprintf("snapshot '%s' does not exist or has expired", req.SnapshotId))
+	}
+	if !released {
+		return nil, accessDenied(principal, "%s may not release snapshot '%s', another principal created it",
+			principal, req.SnapshotId)
+	}
+
+	level.Debug(s.logger).Log("msg", "released snapshot", "snapshot", req.SnapshotId)
+	return &pb.ReleaseSnapshotResponse{
+		Message: fmt.Sprintf("Snapshot '%s' released successfully", req.SnapshotId),
+	}, nil
+}
+
+func (s *server) snapshotLoop() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(snapshotSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			for _, id := range s.snapshots.expire(now) {
+				level.Info(s.logger).Log("msg", "snapshot lease expired", "snapshot", id)
+			}
+		case <-s.done:
+			return
+		}
+	}
+}
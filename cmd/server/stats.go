@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	pb "github.com/TFMV/slatedb_demo/proto/v2"
+	"github.com/go-kit/log/level"
+	flatbuf "github.com/slatedb/slatedb-go/gen"
+	"github.com/thanos-io/objstore"
+)
+
+// storageStatsInterval is how often the storage statistics are collected.
+// Collecting them lists every object of the DB, so it is not done per call.
+const storageStatsInterval = 30 * time.Second
+
+// opCounters count operations since the server started.
+type opCounters struct {
+	startedAt   time.Time
+	puts        atomic.Int64
+	gets        atomic.Int64
+	deletes     atomic.Int64
+	scans       atomic.Int64
+	expirations atomic.Int64
+}
+
+func (c *opCounters) proto() *pb.OpCounters {
+	return &pb.OpCounters{
+		Puts:        c.puts.Load(),
+		Gets:        c.gets.Load(),
+		Deletes:     c.deletes.Load(),
+		Scans:       c.scans.Load(),
+		Expirations: c.expirations.Load(),
+		StartedAt:   c.startedAt.Unix(),
+	}
+}
+
+// storageStats holds the latest storage statistics.
+type storageStats struct {
+	mu      sync.Mutex
+	current *pb.StorageStats
+
+	// sizes caches the size of SSTs and manifests, which never change once
+	// written, so that only new objects are looked up.
+	sizes map[string]int64
+}
+
+func (st *storageStats) get() *pb.StorageStats {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.current == nil {
+		return &pb.StorageStats{}
+	}
+	return st.current
+}
+
+func (s *server) storageStatsLoop() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(storageStatsInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.collectStorageStats(context.Background()); err != nil {
+			level.Warn(s.logger).Log("msg", "collecting storage statistics failed", "err", err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// collectStorageStats lists the objects of the DB and reads its latest
+// manifest.
+func (s *server) collectStorageStats(ctx context.Context) error {
+	s.storage.mu.Lock()
+	cached := s.storage.sizes
+	s.storage.mu.Unlock()
+
+	stats := &pb.StorageStats{}
+	sizes := make(map[string]int64, len(cached))
+	walSizes := make(map[uint64]int64)
+	latestManifest := ""
+
+	walDir := path.Join(s.dbPath, "wal") + "/"
+	err := s.bucket.Iter(ctx, s.dbPath+"/", func(name string) error {
+		immutable := strings.HasSuffix(name, ".sst") || strings.HasSuffix(name, ".manifest")
+
+		size, ok := cached[name]
+		if !ok || !immutable {
+			attrs, err := s.bucket.Attributes(ctx, name)
+			if s.bucket.IsObjNotFoundErr(err) {
+				// Deleted by the compactor since it was listed.
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			size = attrs.Size
+		}
+		if immutable {
+			sizes[name] = size
+		}
+
+		stats.ObjectCount++
+		stats.ObjectBytes += size
+
+		if strings.HasPrefix(name, walDir) {
+			if id, err := strconv.ParseUint(strings.TrimSuffix(path.Base(name), ".sst"), 10, 64); err == nil {
+				walSizes[id] = size
+			}
+		}
+		if path.Dir(name) == s.dbPath && path.Ext(name) == ".manifest" && name > latestManifest {
+			// Manifest IDs are zero-padded, so the latest sorts last.
+			latestManifest = name
+		}
+		return nil
+	}, objstore.WithRecursiveIter)
+	if err != nil {
+		return fmt.Errorf("failed to list objects: %v", err)
+	}
+
+	if latestManifest != "" {
+		if err := s.readManifestStats(ctx, latestManifest, stats); err != nil {
+			return err
+		}
+	}
+	for id, size := range walSizes {
+		if id > stats.WalIdLastCompacted {
+			stats.MemtableSizeBytes += size
+		}
+	}
+	stats.UpdatedAt = time.Now().Unix()
+
+	s.storage.mu.Lock()
+	s.storage.current = stats
+	s.storage.sizes = sizes
+	s.storage.mu.Unlock()
+	return nil
+}
+
+// readManifest reads the manifest name.
+func (s *server) readManifest(ctx context.Context, name string) (*flatbuf.ManifestV1, error) {
+	rc, err := s.bucket.Get(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %v", err)
+	}
+	defer rc.Close()
+
+	buf, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %v", err)
+	}
+	return flatbuf.GetRootAsManifestV1(buf, 0), nil
+}
+
+func (s *server) readManifestStats(ctx context.Context, name string, stats *pb.StorageStats) error {
+	manifest, err := s.readManifest(ctx, name)
+	if err != nil {
+		return err
+	}
+	stats.ManifestId, _ = strconv.ParseUint(strings.TrimSuffix(path.Base(name), ".manifest"), 10, 64)
+	stats.WriterEpoch = manifest.WriterEpoch()
+	stats.CompactorEpoch = manifest.CompactorEpoch()
+	stats.WalIdLastCompacted = manifest.WalIdLastCompacted()
+	stats.WalIdLastSeen = manifest.WalIdLastSeen()
+	stats.L0Ssts = int32(manifest.L0Length())
+	stats.CompactedRuns = int32(manifest.CompactedLength())
+	return nil
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	pb "github.com/TFMV/slatedb_demo/proto/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// watchHistory is the number of recent events kept for watchers that
+	// resume from an earlier sequence.
+	watchHistory = 4096
+
+	// watchBuffer is the number of events queued for a watcher. A watcher
+	// that falls further behind is disconnected and has to resume.
+	watchBuffer = 1024
+)
+
+// watchHub assigns sequence numbers to write events and fans them out to
+// watchers.
+type watchHub struct {
+	mu       sync.Mutex
+	seq      uint64           // sequence of the last event
+	history  []*pb.WatchEvent // most recent events, oldest first
+	watchers map[*watcher]struct{}
+	closed   bool
+}
+
+// watcher receives the events for the stored keys with prefix in ns. Events
+// carry stored keys; Watch converts them to the keys of ns.
+type watcher struct {
+	ns     namespace
+	prefix []byte
+	events chan *pb.WatchEvent
+	lagged bool // set before events is closed if the watcher fell behind
+}
+
+// newWatchHub returns a hub whose sequence numbers start at the current time
+// in microseconds, so that they keep increasing across restarts.
+func newWatchHub() *watchHub {
+	return &watchHub{
+		seq:      uint64(time.Now().UnixMicro()),
+		watchers: make(map[*watcher]struct{}),
+	}
+}
+
+// publish assigns the next sequence number to ev and sends it to every
+// watcher of a matching prefix.
+func (h *watchHub) publish(ev *pb.WatchEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.seq++
+	ev.Sequence = h.seq
+
+	h.history = append(h.history, ev)
+	if len(h.history) >= 2*watchHistory {
+		h.history = append([]*pb.WatchEvent(nil), h.history[len(h.history)-watchHistory:]...)
+	}
+
+	for w := range h.watchers {
+		if !w.matches(ev.Key) {
+			continue
+		}
+		select {
+		case w.events <- ev:
+		default:
+			w.lagged = true
+			close(w.events)
+			delete(h.watchers, w)
+		}
+	}
+}
+
+func (w *watcher) matches(key []byte) bool {
+	return bytes.HasPrefix(key, w.prefix) && w.ns.contains(key)
+}
+
+// subscribe registers a watcher for the keys with prefix in ns. With a
+// non-zero from, it also returns the retained events from that sequence on.
+func (h *watchHub) subscribe(ns namespace, prefix []byte, from uint64) (*watcher, []*pb.WatchEvent, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return nil, nil, failure(codes.Unavailable, reasonShuttingDown, nil, "server is shutting down")
+	}
+
+	w := &watcher{ns: ns, prefix: ns.key(prefix), events: make(chan *pb.WatchEvent, watchBuffer)}
+
+	var replay []*pb.WatchEvent
+	if from > 0 {
+		// Sequence numbers are contiguous within the history.
+		oldest := h.seq - uint64(len(h.history)) + 1
+		if from < oldest {
+			return nil, nil, failure(codes.OutOfRange, reasonHistoryUnavailable,
+				map[string]string{"oldest_sequence": strconv.FormatUint(oldest, 10)},
+				fmt.Sprintf("events from sequence %d are no longer available, the oldest is %d", from, oldest))
+		}
+		for _, ev := range h.history {
+			if ev.Sequence >= from && w.matches(ev.Key) {
+				replay = append(replay, ev)
+			}
+		}
+	}
+
+	h.watchers[w] = struct{}{}
+	return w, replay, nil
+}
+
+func (h *watchHub) unsubscribe(w *watcher) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.watchers, w)
+}
+
+// close ends every watch and rejects new ones. Watch streams never end on
+// their own, so this must be called before the gRPC server stops gracefully.
+func (h *watchHub) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for w := range h.watchers {
+		close(w.events)
+		delete(h.watchers, w)
+	}
+}
+
+// Watch streams the events for keys with req.Prefix until the client goes
+// away or the server shuts down.
+func (s *server) Watch(req *pb.WatchRequest, stream pb.SlateDB_WatchServer) error {
+	ns, err := s.namespace(req.Namespace)
+	if err != nil {
+		return err
+	}
+	w, replay, err := s.watches.subscribe(ns, req.Prefix, req.FromSequence)
+	if err != nil {
+		return err
+	}
+	defer s.watches.unsubscribe(w)
+
+	next := req.FromSequence
+	for _, ev := range replay {
+		if err := stream.Send(w.event(ev)); err != nil {
+			return err
+		}
+		next = ev.Sequence + 1
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return status.FromContextError(stream.Context().Err()).Err()
+		case ev, ok := <-w.events:
+			if !ok {
+				if w.lagged {
+					return failure(codes.ResourceExhausted, reasonWatcherTooSlow,
+						map[string]string{"next_sequence": strconv.FormatUint(next, 10)},
+						fmt.Sprintf("watcher fell behind, resume from sequence %d", next))
+				}
+				return failure(codes.Unavailable, reasonShuttingDown, nil, "server is shutting down")
+			}
+			if err := stream.Send(w.event(ev)); err != nil {
+				return err
+			}
+			next = ev.Sequence + 1
+		}
+	}
+}
+
+// event returns ev with the key of the namespace of w. Events are shared
+// between watchers, so ev itself is not changed.
+func (w *watcher) event(ev *pb.WatchEvent) *pb.WatchEvent {
+	if w.ns == defaultNamespace {
+		return ev
+	}
+	return &pb.WatchEvent{
+		Sequence: ev.Sequence,
+		Type:     ev.Type,
+		Key:      w.ns.external(string(ev.Key)),
+		Value:    ev.Value,
+		ExpireAt: ev.ExpireAt,
+	}
+}
+
+// StopWatches ends all Watch streams. Call it before stopping the gRPC
+// server gracefully.
+func (s *server) StopWatches() {
+	s.watches.close()
+}
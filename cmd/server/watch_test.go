@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+
+	pb "github.com/TFMV/slatedb_demo/proto/v2"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// publishN publishes n puts of the keys prefix0, prefix1... and returns the
+// sequence of the last one.
+func publishN(h *watchHub, prefix string, n int) uint64 {
+	var ev *pb.WatchEvent
+	for i := 0; i < n; i++ {
+		ev = &pb.WatchEvent{Type: pb.WatchEvent_PUT, Key: []byte(fmt.Sprintf("%s%d", prefix, i))}
+		h.publish(ev)
+	}
+	return ev.Sequence
+}
+
+func TestWatchResume(t *testing.T) {
+	h := newWatchHub()
+	first := publishN(h, "a", 1)
+	publishN(h, "b", 1)
+	publishN(h, "a", 3)
+	last := h.seq
+
+	tests := []struct {
+		name   string
+		prefix string
+		from   uint64
+		want   []string
+	}{
+		{"no resume", "", 0, nil},
+		{"from first", "", first, []string{"a0", "b0", "a0", "a1", "a2"}},
+		{"from second", "", first + 1, []string{"b0", "a0", "a1", "a2"}},
+		{"from last", "", last, []string{"a2"}},
+		{"from next", "", last + 1, nil},
+		{"prefix", "a", first, []string{"a0", "a0", "a1", "a2"}},
+		{"other prefix", "b", first + 2, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, replay, err := h.subscribe(defaultNamespace, []byte(tt.prefix), tt.from)
+			if err != nil {
+				t.Fatalf("subscribe from %d failed: %v", tt.from, err)
+			}
+			defer h.unsubscribe(w)
+
+			var got []string
+			for i, ev := range replay {
+				got = append(got, string(ev.Key))
+				if i > 0 && ev.Sequence <= replay[i-1].Sequence {
+					t.Errorf("replayed sequence %d after %d", ev.Sequence, replay[i-1].Sequence)
+				}
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("replayed %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWatchResumeThenLive(t *testing.T) {
+	h := newWatchHub()
+	from := publishN(h, "k", 2) - 1
+
+	w, replay, err := h.subscribe(defaultNamespace, nil, from)
+	if err != nil {
+		t.Fatalf("subscribe failed: %v", err)
+	}
+	defer h.unsubscribe(w)
+	if len(replay) != 2 {
+		t.Fatalf("replayed %d events, want 2", len(replay))
+	}
+
+	// Events published after subscribing are delivered live, and follow
+	// the replayed ones without a gap.
+	publishN(h, "live", 1)
+	ev := <-w.events
+	if want := replay[1].Sequence + 1; ev.Sequence != want {
+		t.Errorf("live event has sequence %d, want %d", ev.Sequence, want)
+	}
+}
+
+func TestWatchResumeInNamespace(t *testing.T) {
+	h := newWatchHub()
+	ns := newNamespace("a")
+	from := h.seq + 1
+	h.publish(&pb.WatchEvent{Type: pb.WatchEvent_PUT, Key: ns.key([]byte("k"))})
+	h.publish(&pb.WatchEvent{Type: pb.WatchEvent_PUT, Key: newNamespace("ab").key([]byte("k"))})
+	h.publish(&pb.WatchEvent{Type: pb.WatchEvent_PUT, Key: []byte("k")})
+
+	w, replay, err := h.subscribe(ns, nil, from)
+	if err != nil {
+		t.Fatalf("subscribe failed: %v", err)
+	}
+	defer h.unsubscribe(w)
+	if len(replay) != 1 || string(w.event(replay[0]).Key) != "k" {
+		t.Errorf("replayed %v, want only key k of namespace a", replay)
+	}
+}
+
+func TestWatchHistoryUnavailable(t *testing.T) {
+	h := newWatchHub()
+	start := h.seq + 1
+	last := publishN(h, "k", 3*watchHistory)
+	oldest := last - watchHistory + 1
+
+	tests := []struct {
+		name    string
+		from    uint64
+		replay  int
+		wantErr bool
+	}{
+		{"oldest retained", oldest, watchHistory, false},
+		{"newest", last, 1, false},
+		{"just evicted", oldest - 1, 0, true},
+		{"first ever", start, 0, true},
+		{"sequence 1", 1, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, replay, err := h.subscribe(defaultNamespace, nil, tt.from)
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("subscribe from %d failed: %v", tt.from, err)
+				}
+				h.unsubscribe(w)
+				if len(replay) != tt.replay {
+					t.Errorf("replayed %d events, want %d", len(replay), tt.replay)
+				}
+				return
+			}
+
+			st := status.Convert(err)
+			if st.Code() != codes.OutOfRange {
+				t.Fatalf("subscribe from %d = %v, want OUT_OF_RANGE", tt.from, err)
+			}
+			var info *errdetails.ErrorInfo
+			for _, detail := range st.Details() {
+				if d, ok := detail.(*errdetails.ErrorInfo); ok {
+					info = d
+				}
+			}
+			if info == nil || info.Reason != reasonHistoryUnavailable {
+				t.Fatalf("error details %v, want reason %s", st.Details(), reasonHistoryUnavailable)
+			}
+			if got := info.Metadata["oldest_sequence"]; got != strconv.FormatUint(oldest, 10) {
+				t.Errorf("oldest_sequence = %s, want %d", got, oldest)
+			}
+		})
+	}
+}
+
+func TestWatchLaggingWatcher(t *testing.T) {
+	h := newWatchHub()
+	w, _, err := h.subscribe(defaultNamespace, nil, 0)
+	if err != nil {
+		t.Fatalf("subscribe failed: %v", err)
+	}
+
+	publishN(h, "k", watchBuffer+1)
+	n := 0
+	for range w.events {
+		n++
+	}
+	if n != watchBuffer || !w.lagged {
+		t.Errorf("lagging watcher received %d events, lagged %v; want %d events and lagged", n, w.lagged, watchBuffer)
+	}
+}
@@ -0,0 +1,441 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/chzyer/readline"
+	"github.com/fatih/color"
+)
+
+const (
+	// historyFile is the name of the shell history in the home directory.
+	historyFile  = ".slatedb_history"
+	historyLimit = 1000
+
+	// completionLimit and completionTimeout bound the prefix scan done to
+	// complete a key, so that Tab stays responsive.
+	completionLimit   = 200
+	completionTimeout = time.Second
+)
+
+const shellHelp = `Shell commands:
+  use [prefix]                         set the working prefix, or clear it
+  menu                                 open the interactive menu
+  help                                 show this help
+  exit                                 leave the shell
+
+Keys, prefixes and range bounds are relative to the working prefix. Quote
+values containing spaces with ' or ". A quoted value may span several lines,
+and a line ending in \ continues on the next one. Press Tab to complete
+commands, flags and keys.
+`
+
+// shellCommands are the commands completed at the start of a line.
+var shellCommands = []string{
+	"put", "get", "del", "purge", "scan", "range", "stats", "ns", "snapshot", "export", "import",
+	"use", "menu", "help", "exit",
+}
+
+// console reads the lines of the shell and the answers to menu prompts.
+var console *readline.Instance
+
+// newConsole returns the line reader of the interactive mode, with the
+// history kept in ~/.slatedb_history.
+func newConsole(sh *shell) (*readline.Instance, error) {
+	cfg := &readline.Config{
+		HistoryLimit:           historyLimit,
+		DisableAutoSaveHistory: true,
+		AutoComplete:           shellCompleter{sh},
+		InterruptPrompt:        "^C",
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		cfg.HistoryFile = filepath.Join(home, historyFile)
+	}
+	return readline.NewEx(cfg)
+}
+
+// readLine reads a line from the console. Closing the input, with Ctrl-D
+// or at the end of piped input, exits the CLI.
+func readLine(prompt string) string {
+	console.SetPrompt(prompt)
+	line, err := console.Readline()
+	if err == io.EOF {
+		successColor.Println("Exiting SlateDB CLI. Goodbye!")
+		os.Exit(0)
+	}
+	return strings.TrimSpace(line)
+}
+
+// shell runs commands typed at a prompt, with the same syntax as the
+// command line.
+type shell struct {
+	client *SlateDBClient
+	opts   cliOptions
+}
+
+// prompt shows the namespace of the shell, if it is not the default one,
+// and the working prefix.
+func (sh *shell) prompt() string {
+	name := "slatedb"
+	if sh.opts.namespace != "" {
+		name += "[" + sh.opts.namespace + "]"
+	}
+	if sh.opts.prefix == "" {
+		return promptColor.Sprintf("%s> ", name)
+	}
+	return promptColor.Sprintf("%s %s> ", name, formatBytes([]byte(sh.opts.prefix)))
+}
+
+// run reads and runs commands until exit or the end of the input.
+func (sh *shell) run() {
+	for {
+		words, line, err := sh.readCommand()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			errorColor.Printf("✗ %v\n", err)
+			continue
+		}
+		if len(words) == 0 {
+			continue
+		}
+
+		// Multi-line commands are left out, as the history file holds a
+		// command per line.
+		if !strings.Contains(line, "\n") {
+			console.SaveHistory(line)
+		}
+		if words[0] == "exit" || words[0] == "quit" {
+			break
+		}
+		sh.exec(words)
+	}
+	successColor.Println("Exiting SlateDB CLI. Goodbye!")
+}
+
+// readCommand reads a command, continuing on the next lines while a quote is
+// open or a line ends in a backslash. It returns the words of the command
+// and its text. Ctrl-C discards the command being typed.
+func (sh *shell) readCommand() ([]string, string, error) {
+	console.SetPrompt(sh.prompt())
+	text := ""
+	for {
+		line, err := console.Readline()
+		if err == readline.ErrInterrupt {
+			return nil, "", nil
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		text += line
+
+		words, err := splitLine(text)
+		switch {
+		case errors.Is(err, errOpenQuote):
+			text += "\n"
+		case errors.Is(err, errContinued):
+			text = strings.TrimSuffix(text, `\`)
+		default:
+			return words, text, err
+		}
+		console.SetPrompt(promptColor.Sprint("... "))
+	}
+}
+
+// exec runs a single command.
+func (sh *shell) exec(words []string) {
+	switch words[0] {
+	case "help":
+		fmt.Print(commandList, "\n", shellHelp)
+		return
+	case "use":
+		sh.use(words[1:])
+		return
+	case "menu":
+		runMenu(sh.client)
+		return
+	}
+
+	err := sh.runCommand(words[0], words[1:])
+	var usageErr usageError
+	if errors.As(err, &usageErr) {
+		errorColor.Fprintf(os.Stderr, "✗ %v\n", err)
+		fmt.Fprintln(os.Stderr, "Type 'help' for usage.")
+		return
+	}
+	reportError(err)
+}
+
+// runCommand runs one of the commands of the command line, with the
+// connection of the shell.
+func (sh *shell) runCommand(name string, args []string) error {
+	opts := sh.opts
+	fs, run, err := newCommand(name, &opts)
+	if err != nil {
+		return err
+	}
+	args, err = parseCommandArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if opts.addr != sh.opts.addr || opts.replicas.String() != sh.opts.replicas.String() ||
+		opts.tls != sh.opts.tls || opts.token != sh.opts.token {
+		return usagef("--addr, --replica, --token and the TLS flags cannot be changed in the shell")
+	}
+	if opts.noColor {
+		color.NoColor = true
+	}
+
+	// The timeouts and retries given to the command apply to it only.
+	c := sh.client.with(opts.clientOptions()...)
+	if opts.snapshot != "" {
+		c = c.Primary()
+	}
+
+	// Ctrl-C cancels the command instead of leaving the shell.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	return run(ctx, c, args)
+}
+
+func (sh *shell) use(args []string) {
+	switch len(args) {
+	case 0:
+		sh.opts.prefix = ""
+		infoColor.Println("ℹ Working prefix cleared")
+	case 1:
+		prefix, err := parseBinary(args[0])
+		if err != nil {
+			errorColor.Printf("✗ Invalid prefix: %v\n", err)
+			return
+		}
+		sh.opts.prefix = prefix
+		infoColor.Printf("ℹ Working prefix is now '%s'\n", formatBytes([]byte(prefix)))
+	default:
+		errorColor.Println("✗ use takes at most one prefix")
+	}
+}
+
+var (
+	errOpenQuote = errors.New("unterminated quote")
+	errContinued = errors.New("line continues")
+)
+
+// splitLine splits a command into words. Words are separated by spaces;
+// single quotes keep their content as is, and within double quotes and
+// outside quotes a backslash escapes the next character. It returns
+// errOpenQuote if a quote is not closed and errContinued if the line ends
+// in a backslash.
+func splitLine(line string) ([]string, error) {
+	var (
+		words  []string
+		word   strings.Builder
+		inWord bool
+		quote  rune
+		escape bool
+	)
+	for _, r := range line {
+		switch {
+		case escape:
+			word.WriteRune(r)
+			escape = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\\':
+			escape = true
+			inWord = true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	switch {
+	case quote != 0:
+		return nil, errOpenQuote
+	case escape:
+		return nil, errContinued
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// shellCompleter completes commands, flags and keys when Tab is pressed.
+type shellCompleter struct {
+	sh *shell
+}
+
+func (c shellCompleter) Do(line []rune, pos int) ([][]rune, int) {
+	text := string(line[:pos])
+	start := strings.LastIndexAny(text, " \t") + 1
+	words := strings.Fields(text[:start])
+	word := text[start:]
+
+	var candidates []string
+	switch {
+	case len(words) == 0:
+		for _, name := range shellCommands {
+			candidates = append(candidates, name+" ")
+		}
+	case strings.HasPrefix(word, "-"):
+		candidates = flagCandidates(words[0], word)
+	case completesKey(words):
+		candidates = c.sh.keyCandidates(word)
+	}
+
+	var completions [][]rune
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, word) {
+			completions = append(completions, []rune(candidate[len(word):]))
+		}
+	}
+	return completions, len([]rune(word))
+}
+
+// commandFlags returns the flags of a command of the command line, or an
+// empty set for the commands of the shell.
+func commandFlags(name string) *flag.FlagSet {
+	var opts cliOptions
+	fs, _, err := newCommand(name, &opts)
+	if err != nil {
+		return flag.NewFlagSet(name, flag.ContinueOnError)
+	}
+	return fs
+}
+
+// flagCandidates returns the flags of command, written with the dashes
+// used in word.
+func flagCandidates(command, word string) []string {
+	fs := commandFlags(command)
+	dashes := "--"
+	if !strings.HasPrefix(word, "--") {
+		dashes = "-"
+	}
+
+	var candidates []string
+	fs.VisitAll(func(f *flag.Flag) {
+		candidates = append(candidates, dashes+f.Name+" ")
+	})
+	return candidates
+}
+
+// keyFlags are the flags whose value is a key or a prefix.
+var keyFlags = map[string]bool{"prefix": true, "start": true, "end": true}
+
+// completesKey reports whether the word after words is a key: the value of a
+// key flag, the key of get, del and put, or the prefix of use.
+func completesKey(words []string) bool {
+	command, args := words[0], words[1:]
+	if len(args) > 0 && strings.HasPrefix(args[len(args)-1], "-") {
+		name := strings.TrimLeft(args[len(args)-1], "-")
+		if f := commandFlags(command).Lookup(name); f != nil && !isBoolFlag(f) {
+			return keyFlags[name]
+		}
+	}
+
+	switch command {
+	case "get", "del", "delete", "use":
+	case "put":
+		return len(positionalArgs(command, args)) == 0
+	default:
+		return false
+	}
+	return len(positionalArgs(command, args)) == 0
+}
+
+// positionalArgs returns the arguments of command that are not flags or flag
+// values.
+func positionalArgs(command string, args []string) []string {
+	fs := commandFlags(command)
+
+	var positional []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") {
+			positional = append(positional, arg)
+			continue
+		}
+		name := strings.TrimLeft(arg, "-")
+		if strings.Contains(name, "=") {
+			continue
+		}
+		if f := fs.Lookup(name); f != nil && !isBoolFlag(f) {
+			i++ // skip the value
+		}
+	}
+	return positional
+}
+
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+// keyCandidates returns the completions of word as a key relative to the
+// working prefix. Keys are completed one segment at a time, up to the next
+// colon, and only printable keys are offered.
+func (sh *shell) keyCandidates(word string) []string {
+	if strings.HasPrefix(word, hexPrefix) || strings.HasPrefix(word, base64Prefix) {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+	defer cancel()
+
+	entries, err := sh.client.db.PrefixScan(ctx, []byte(sh.opts.prefix+word), completionLimit)
+	if err != nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var candidates []string
+	for _, entry := range entries {
+		key := string(entry.Key[len(sh.opts.prefix):])
+		if formatBytes([]byte(key)) != key {
+			continue
+		}
+		if i := strings.IndexByte(key[len(word):], ':'); i >= 0 {
+			key = key[:len(word)+i+1]
+		} else {
+			key += " "
+		}
+		if !seen[key] {
+			seen[key] = true
+			candidates = append(candidates, key)
+		}
+	}
+	sort.Strings(candidates)
+	return candidates
+}
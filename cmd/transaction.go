@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	pb "github.com/TFMV/slatedb_demo/proto/v2"
+	"github.com/fatih/color"
+	"github.com/rodaine/table"
+)
+
+// stagedOps holds the operations of the transaction being built.
+var stagedOps []*pb.WriteOperation
+
+func showTransactionMenu(staged int) int {
+	titleColor.Print("\n=== Transaction Builder ===\n\n")
+	infoColor.Printf("%d operation(s) staged\n\n", staged)
+	fmt.Println("1. Stage Put")
+	fmt.Println("2. Stage Delete")
+	fmt.Println("3. Show Staged Operations")
+	fmt.Println("4. Commit")
+	fmt.Println("5. Discard")
+	fmt.Println("0. Back to Main Menu")
+	fmt.Println()
+
+	return readIntInput("Choose an option: ")
+}
+
+// handleTransactionBuilder stages puts and deletes locally and sends them to
+// the server in a single Write, so they are applied all-or-nothing. Staged
+// operations are kept when returning to the main menu.
+func handleTransactionBuilder(client *SlateDBClient) {
+	for {
+		choice := showTransactionMenu(len(stagedOps))
+
+		switch choice {
+		case 1: // Stage Put
+			key := readBinaryInput("Enter key")
+			if key == "" {
+				errorColor.Println("✗ Key cannot be empty")
+				continue
+			}
+			value := readBinaryInput("Enter value")
+
+			stagedOps = append(stagedOps, &pb.WriteOperation{
+				Op: &pb.WriteOperation_Put{Put: &pb.KeyValue{Key: []byte(key), Value: []byte(value)}},
+			})
+			successColor.Printf("✓ Staged put of '%s'\n", formatBytes([]byte(key)))
+
+		case 2: // Stage Delete
+			key := readBinaryInput("Enter key")
+			if key == "" {
+				errorColor.Println("✗ Key cannot be empty")
+				continue
+			}
+
+			stagedOps = append(stagedOps, &pb.WriteOperation{
+				Op: &pb.WriteOperation_Delete{Delete: []byte(key)},
+			})
+			successColor.Printf("✓ Staged delete of '%s'\n", formatBytes([]byte(key)))
+
+		case 3: // Show Staged Operations
+			if len(stagedOps) == 0 {
+				infoColor.Println("No operations staged.")
+				continue
+			}
+			displayWriteOperations(stagedOps)
+
+		case 4: // Commit
+			if len(stagedOps) == 0 {
+				infoColor.Println("No operations staged.")
+				continue
+			}
+
+			if err := client.Write(context.Background(), stagedOps); err != nil {
+				// Keep the staged operations so they can be fixed and retried.
+				errorColor.Printf("✗ Error: %v\n", err)
+				continue
+			}
+			stagedOps = nil
+
+		case 5: // Discard
+			infoColor.Printf("Discarded %d staged operation(s)\n", len(stagedOps))
+			stagedOps = nil
+
+		case 0: // Back to main menu
+			return
+
+		default:
+			errorColor.Println("Invalid option. Please try again.")
+		}
+	}
+}
+
+func displayWriteOperations(ops []*pb.WriteOperation) {
+	headerFmt := color.New(color.FgHiCyan, color.Underline).SprintfFunc()
+	columnFmt := color.New(color.FgHiWhite).SprintfFunc()
+
+	tbl := table.New("#", "Op", "Key", "Value")
+	tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+
+	for i, op := range ops {
+		switch op := op.Op.(type) {
+		case *pb.WriteOperation_Put:
+			tbl.AddRow(i+1, "PUT", formatBytes(op.Put.Key), formatBytes(op.Put.Value))
+		case *pb.WriteOperation_Delete:
+			tbl.AddRow(i+1, "DELETE", formatBytes(op.Delete), "")
+		}
+	}
+
+	tbl.Print()
+	fmt.Println()
+}
@@ -0,0 +1,522 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "github.com/TFMV/slatedb_demo/proto/v2"
+)
+
+// Export and import copy a keyspace to and from NDJSON or CSV files, in the
+// same record format as the scan command prints. Both record their position
+// in a checkpoint file after every batch, so that an interrupted run can be
+// continued with --resume.
+
+const (
+	defaultExportBatchSize = 1000
+	defaultImportBatchSize = 500
+
+	// dryRunExamples is the number of remapped keys shown by a dry-run import.
+	dryRunExamples = 3
+)
+
+// transferOptions are the flags shared by export and import.
+type transferOptions struct {
+	file       string
+	format     outputFormat // ndjson or csv
+	batchSize  int
+	checkpoint string
+	resume     bool
+	dryRun     bool
+}
+
+func (o *transferOptions) register(fs *flag.FlagSet, batchSize int) {
+	fs.StringVar(&o.file, "file", "", "`path` of the dump file")
+	fs.Var(&o.format, "format", "file `format`: ndjson or csv (default from the file extension)")
+	fs.IntVar(&o.batchSize, "batch-size", batchSize, "entries per request")
+	fs.StringVar(&o.checkpoint, "checkpoint", "", "checkpoint `path` (default the file name with .checkpoint appended)")
+	fs.BoolVar(&o.resume, "resume", false, "continue from the checkpoint of an interrupted run")
+	fs.BoolVar(&o.dryRun, "dry-run", false, "read everything and report what would be done, without writing")
+}
+
+// validate checks the flags and fills in the defaults that depend on the
+// file name.
+func (o *transferOptions) validate() error {
+	if o.file == "" {
+		return usagef("--file is required")
+	}
+	if o.batchSize <= 0 {
+		return usagef("--batch-size must be positive")
+	}
+	switch o.format {
+	case "":
+		o.format = outputNDJSON
+		if strings.EqualFold(filepath.Ext(o.file), ".csv") {
+			o.format = outputCSV
+		}
+	case outputNDJSON, outputCSV:
+	default:
+		return usagef("unsupported file format %q, use ndjson or csv", o.format)
+	}
+	if o.checkpoint == "" {
+		o.checkpoint = o.file + ".checkpoint"
+	}
+	return nil
+}
+
+// transferCheckpoint records how far an export or import got. It is saved
+// once the batch it describes is durable: synced to the file for an export,
+// acknowledged by the server for an import.
+type transferCheckpoint struct {
+	File    string       `json:"file"`
+	Format  outputFormat `json:"format"`
+	Offset  int64        `json:"offset"`  // bytes of the file written or read
+	Entries int64        `json:"entries"` // entries written or read
+
+	// Export only.
+	Prefix    []byte `json:"prefix,omitempty"`
+	PageToken string `json:"page_token,omitempty"`
+
+	// Import only.
+	Imported int64 `json:"imported,omitempty"`
+	Expired  int64 `json:"expired,omitempty"`
+	Failed   int64 `json:"failed,omitempty"`
+}
+
+// loadCheckpoint returns the checkpoint saved at path, or nil if there is
+// none.
+func loadCheckpoint(path string) (*transferCheckpoint, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %v", err)
+	}
+
+	var cp transferCheckpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint %s: %v", path, err)
+	}
+	return &cp, nil
+}
+
+// saveCheckpoint replaces the checkpoint at path atomically.
+func saveCheckpoint(path string, cp *transferCheckpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to save checkpoint: %v", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to save checkpoint: %v", err)
+	}
+	return nil
+}
+
+func removeCheckpoint(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove checkpoint: %v", err)
+	}
+	return nil
+}
+
+// resumeCheckpoint returns the checkpoint to continue from with --resume, or
+// fresh if there is none. match reports whether a saved checkpoint belongs
+// to this run.
+func resumeCheckpoint(c *SlateDBClient, o transferOptions, fresh *transferCheckpoint, match func(*transferCheckpoint) bool) (*transferCheckpoint, error) {
+	if !o.resume || o.dryRun {
+		return fresh, nil
+	}
+
+	saved, err := loadCheckpoint(o.checkpoint)
+	if err != nil {
+		return nil, err
+	}
+	if saved == nil {
+		c.info("ℹ No checkpoint found at %s, starting from the beginning\n", o.checkpoint)
+		return fresh, nil
+	}
+	if saved.File != o.file || saved.Format != o.format || !match(saved) {
+		return nil, fmt.Errorf("checkpoint %s belongs to a different transfer", o.checkpoint)
+	}
+
+	c.info("ℹ Resuming after %d entries\n", saved.Entries)
+	return saved, nil
+}
+
+// runExport writes the entries with prefix to o.file, one page of scan
+// results at a time.
+func runExport(ctx context.Context, c *SlateDBClient, prefix string, o transferOptions) error {
+	cp, err := resumeCheckpoint(c, o,
+		&transferCheckpoint{File: o.file, Format: o.format, Prefix: []byte(prefix)},
+		func(saved *transferCheckpoint) bool { return string(saved.Prefix) == prefix })
+	if err != nil {
+		return err
+	}
+
+	var f *os.File
+	if !o.dryRun {
+		f, err = os.OpenFile(o.file, os.O_WRONLY|os.O_CREATE, 0o644)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %v", o.file, err)
+		}
+		defer f.Close()
+
+		// Drop anything written after the checkpoint, then append.
+		if err := f.Truncate(cp.Offset); err != nil {
+			return fmt.Errorf("failed to truncate %s: %v", o.file, err)
+		}
+		if _, err := f.Seek(cp.Offset, io.SeekStart); err != nil {
+			return fmt.Errorf("failed to seek %s: %v", o.file, err)
+		}
+	}
+
+	bar := newProgressBar("Exporting", c.countPrefix(ctx, prefix))
+	for {
+		entries, next, err := c.db.PrefixScanPage(ctx, []byte(prefix), int32(o.batchSize), cp.PageToken)
+		if err != nil {
+			return err
+		}
+
+		var buf bytes.Buffer
+		if err := encodeEntries(&buf, o.format, entries, cp.Offset == 0); err != nil {
+			return err
+		}
+		if f != nil {
+			if _, err := f.Write(buf.Bytes()); err != nil {
+				return fmt.Errorf("failed to write %s: %v", o.file, err)
+			}
+			if err := f.Sync(); err != nil {
+				return fmt.Errorf("failed to write %s: %v", o.file, err)
+			}
+		}
+
+		cp.Offset += int64(buf.Len())
+		cp.Entries += int64(len(entries))
+		cp.PageToken = next
+		bar.update(cp.Entries, cp.Entries)
+		if next == "" {
+			break
+		}
+		if f != nil {
+			if err := saveCheckpoint(o.checkpoint, cp); err != nil {
+				return err
+			}
+		}
+	}
+	bar.finish(cp.Entries, cp.Entries)
+
+	if o.dryRun {
+		c.success("✓ Dry run: would export %d entries (%d bytes) to %s\n", cp.Entries, cp.Offset, o.file)
+		return nil
+	}
+	if err := removeCheckpoint(o.checkpoint); err != nil {
+		return err
+	}
+	c.success("✓ Exported %d entries to %s\n", cp.Entries, o.file)
+	return nil
+}
+
+// encodeEntries writes entries in format, starting with the CSV header if
+// header is set.
+func encodeEntries(w io.Writer, format outputFormat, entries []*pb.KeyValue, header bool) error {
+	if format == outputCSV {
+		cw := csv.NewWriter(w)
+		if header {
+			cw.Write(entryCSVHeader)
+		}
+		for _, entry := range entries {
+			cw.Write(entryRecordOf(entry).csv())
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+
+	enc := json.NewEncoder(w)
+	for _, entry := range entries {
+		if err := enc.Encode(entryRecordOf(entry)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// countPrefix returns the number of keys with prefix, or zero if the server
+// cannot tell. It is only used to size the progress bar.
+func (c *SlateDBClient) countPrefix(ctx context.Context, prefix string) int64 {
+	resp, err := c.db.GetStats(ctx, []byte(prefix))
+	if err != nil || len(resp.Prefixes) == 0 {
+		return 0
+	}
+	return resp.Prefixes[0].Keys
+}
+
+// prefixRemap replaces the prefix from of imported keys with to.
+type prefixRemap struct {
+	from, to string
+}
+
+// remapList is the repeatable --remap flag, written as from=to.
+type remapList []prefixRemap
+
+func (l *remapList) String() string {
+	rules := make([]string, len(*l))
+	for i, r := range *l {
+		rules[i] = formatBytes([]byte(r.from)) + "=" + formatBytes([]byte(r.to))
+	}
+	return strings.Join(rules, ",")
+}
+
+func (l *remapList) Set(value string) error {
+	fromArg, toArg, ok := strings.Cut(value, "=")
+	if !ok || fromArg == "" {
+		return fmt.Errorf("expected from=to, got %q", value)
+	}
+	from, err := parseBinary(fromArg)
+	if err != nil {
+		return fmt.Errorf("invalid prefix %q: %v", fromArg, err)
+	}
+	to, err := parseBinary(toArg)
+	if err != nil {
+		return fmt.Errorf("invalid prefix %q: %v", toArg, err)
+	}
+	*l = append(*l, prefixRemap{from: from, to: to})
+	return nil
+}
+
+// apply returns key with the first matching prefix replaced. Keys that match
+// no rule are returned unchanged.
+func (l remapList) apply(key string) string {
+	for _, r := range l {
+		if strings.HasPrefix(key, r.from) {
+			return r.to + key[len(r.from):]
+		}
+	}
+	return key
+}
+
+// runImport stores the entries of o.file with BatchPut, o.batchSize entries
+// per request. Entries that have already expired are skipped.
+func runImport(ctx context.Context, c *SlateDBClient, remaps remapList, o transferOptions) error {
+	f, err := os.Open(o.file)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %v", o.file, err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %v", o.file, err)
+	}
+
+	cp, err := resumeCheckpoint(c, o,
+		&transferCheckpoint{File: o.file, Format: o.format},
+		func(*transferCheckpoint) bool { return true })
+	if err != nil {
+		return err
+	}
+	if _, err := f.Seek(cp.Offset, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek %s: %v", o.file, err)
+	}
+
+	var reader entryReader
+	if o.format == outputCSV {
+		reader = newCSVEntryReader(f, cp.Offset)
+	} else {
+		reader = &ndjsonEntryReader{r: bufio.NewReader(f), off: cp.Offset}
+	}
+
+	bar := newProgressBar("Importing", info.Size())
+	batch := make([]*pb.KeyValue, 0, o.batchSize)
+	examples := 0
+
+	flush := func() error {
+		if len(batch) > 0 {
+			if o.dryRun {
+				cp.Imported += int64(len(batch))
+			} else {
+				result, err := c.db.BatchPut(ctx, batch)
+				if err != nil {
+					return err
+				}
+				cp.Imported += int64(result.Succeeded)
+				cp.Failed += int64(result.Failed)
+			}
+			batch = batch[:0]
+		}
+
+		cp.Offset = reader.offset()
+		bar.update(cp.Offset, cp.Entries)
+		if o.dryRun {
+			return nil
+		}
+		return saveCheckpoint(o.checkpoint, cp)
+	}
+
+	now := time.Now().Unix()
+	for {
+		record, err := reader.next()
+		if err == io.EOF {
+			break
+		}
+		var entry *pb.KeyValue
+		if err == nil {
+			entry, err = record.keyValue()
+		}
+		if err != nil {
+			return fmt.Errorf("%s: entry %d: %v", o.file, cp.Entries+1, err)
+		}
+
+		cp.Entries++
+		if entry.ExpireAt != 0 && entry.ExpireAt <= now {
+			cp.Expired++
+			continue
+		}
+
+		key := remaps.apply(string(entry.Key))
+		if o.dryRun && key != string(entry.Key) && examples < dryRunExamples {
+			c.info("ℹ Would import '%s' as '%s'\n", formatBytes(entry.Key), formatBytes([]byte(key)))
+			examples++
+		}
+		entry.Key = []byte(key)
+
+		batch = append(batch, entry)
+		if len(batch) == o.batchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+	bar.finish(cp.Offset, cp.Entries)
+
+	if o.dryRun {
+		c.success("✓ Dry run: would import %d entries from %s (%d expired entries skipped)\n",
+			cp.Imported, o.file, cp.Expired)
+		return nil
+	}
+	if err := removeCheckpoint(o.checkpoint); err != nil {
+		return err
+	}
+	c.success("✓ Imported %d entries from %s (%d expired entries skipped)\n",
+		cp.Imported, o.file, cp.Expired)
+	if cp.Failed > 0 {
+		return fmt.Errorf("%d entries were rejected by the server", cp.Failed)
+	}
+	return nil
+}
+
+// keyValue decodes the key and value of r.
+func (r entryRecord) keyValue() (*pb.KeyValue, error) {
+	key, err := parseBinary(r.Key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key: %v", err)
+	}
+	if key == "" {
+		return nil, errors.New("key cannot be empty")
+	}
+	value, err := parseBinary(r.Value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value: %v", err)
+	}
+	return &pb.KeyValue{Key: []byte(key), Value: []byte(value), ExpireAt: r.ExpireAt}, nil
+}
+
+// entryReader reads the records of a dump file.
+type entryReader interface {
+	// next returns the next record, or io.EOF after the last one.
+	next() (entryRecord, error)
+	// offset returns the file offset just past the last record returned.
+	offset() int64
+}
+
+type ndjsonEntryReader struct {
+	r   *bufio.Reader
+	off int64
+}
+
+func (r *ndjsonEntryReader) next() (entryRecord, error) {
+	for {
+		line, err := r.r.ReadBytes('\n')
+		if len(line) == 0 && err != nil {
+			return entryRecord{}, err
+		}
+		if err != nil && err != io.EOF {
+			return entryRecord{}, err
+		}
+		r.off += int64(len(line))
+
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		var record entryRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			return entryRecord{}, err
+		}
+		return record, nil
+	}
+}
+
+func (r *ndjsonEntryReader) offset() int64 { return r.off }
+
+type csvEntryReader struct {
+	r    *csv.Reader
+	base int64 // file offset the reader started at
+
+	headerChecked bool
+}
+
+// newCSVEntryReader reads CSV records from r, which starts at offset base of
+// the file. A header row is only expected at the start of the file.
+func newCSVEntryReader(r io.Reader, base int64) *csvEntryReader {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	return &csvEntryReader{r: cr, base: base, headerChecked: base > 0}
+}
+
+func (r *csvEntryReader) next() (entryRecord, error) {
+	fields, err := r.r.Read()
+	if err != nil {
+		return entryRecord{}, err
+	}
+	if !r.headerChecked {
+		r.headerChecked = true
+		if slices.Equal(fields, entryCSVHeader) || slices.Equal(fields, entryCSVHeader[:2]) {
+			return r.next()
+		}
+	}
+
+	if len(fields) != 2 && len(fields) != 3 {
+		return entryRecord{}, fmt.Errorf("expected key,value[,expire_at], got %d fields", len(fields))
+	}
+	record := entryRecord{Key: fields[0], Value: fields[1]}
+	if len(fields) == 3 && fields[2] != "" {
+		record.ExpireAt, err = strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return entryRecord{}, fmt.Errorf("invalid expire_at %q", fields[2])
+		}
+	}
+	return record, nil
+}
+
+func (r *csvEntryReader) offset() int64 { return r.base + r.r.InputOffset() }
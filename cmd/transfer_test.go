@@ -0,0 +1,599 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TFMV/slatedb_demo/client"
+	pb "github.com/TFMV/slatedb_demo/proto/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// transferServer is an in-memory server with the RPCs used by export and
+// import. Page tokens are the last key of the previous page.
+type transferServer struct {
+	pb.UnimplementedSlateDBServer
+
+	mu      sync.Mutex
+	entries map[string]*pb.KeyValue
+	puts    map[string]int // number of times each key was put
+
+	scans, batches int
+	failScanAt     int // the PrefixScan call that fails, counting from 1; 0 for none
+	failBatchAt    int // the BatchPut call that fails, counting from 1; 0 for none
+}
+
+func newTransferServer(entries []*pb.KeyValue) *transferServer {
+	s := &transferServer{entries: make(map[string]*pb.KeyValue), puts: make(map[string]int)}
+	for _, entry := range entries {
+		s.entries[string(entry.Key)] = entry
+	}
+	return s
+}
+
+func (s *transferServer) PrefixScan(ctx context.Context, req *pb.PrefixScanRequest) (*pb.PrefixScanResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.scans++
+	if s.scans == s.failScanAt {
+		return nil, status.Error(codes.Internal, "injected failure")
+	}
+
+	var keys []string
+	for key := range s.entries {
+		if strings.HasPrefix(key, string(req.Prefix)) && key > req.PageToken {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	resp := &pb.PrefixScanResponse{}
+	if req.Limit > 0 && len(keys) > int(req.Limit) {
+		keys = keys[:req.Limit]
+		resp.NextPageToken = keys[len(keys)-1]
+	}
+	for _, key := range keys {
+		resp.Entries = append(resp.Entries, s.entries[key])
+	}
+	return resp, nil
+}
+
+func (s *transferServer) BatchPut(ctx context.Context, req *pb.BatchPutRequest) (*pb.BatchPutResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.batches++
+	if s.batches == s.failBatchAt {
+		return nil, status.Error(codes.Unavailable, "injected failure")
+	}
+	for _, entry := range req.Entries {
+		s.entries[string(entry.Key)] = entry
+		s.puts[string(entry.Key)]++
+	}
+	return &pb.BatchPutResponse{SuccessCount: int32(len(req.Entries))}, nil
+}
+
+// dial returns a client connected to s over an in-process connection.
+func (s *transferServer) dial(t *testing.T) *SlateDBClient {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	pb.RegisterSlateDBServer(srv, s)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	c, err := NewSlateDBClient(
+		client.WithAddress("passthrough:///bufconn"),
+		client.WithInsecure(),
+		client.WithDialOptions(grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		})),
+	)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	t.Cleanup(c.Close)
+	return c
+}
+
+// testEntries returns n entries with the keys prefix000, prefix001...
+// Every third value is binary and every fourth entry expires.
+func testEntries(prefix string, n int) []*pb.KeyValue {
+	expireAt := time.Now().Add(time.Hour).Unix()
+	entries := make([]*pb.KeyValue, n)
+	for i := range entries {
+		entry := &pb.KeyValue{
+			Key:   []byte(fmt.Sprintf("%s%03d", prefix, i)),
+			Value: []byte(fmt.Sprintf("value, \"%d\"", i)),
+		}
+		if i%3 == 0 {
+			entry.Value = []byte{0, byte(i), 0xff}
+		}
+		if i%4 == 0 {
+			entry.ExpireAt = expireAt
+		}
+		entries[i] = entry
+	}
+	return entries
+}
+
+// readEntries decodes every record of a dump file.
+func readEntries(t *testing.T, data []byte, format outputFormat) []*pb.KeyValue {
+	t.Helper()
+
+	var reader entryReader
+	if format == outputCSV {
+		reader = newCSVEntryReader(bytes.NewReader(data), 0)
+	} else {
+		reader = &ndjsonEntryReader{r: bufio.NewReader(bytes.NewReader(data))}
+	}
+	var entries []*pb.KeyValue
+	for {
+		record, err := reader.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("failed to read entry %d: %v", len(entries)+1, err)
+		}
+		entry, err := record.keyValue()
+		if err != nil {
+			t.Fatalf("failed to decode entry %d: %v", len(entries)+1, err)
+		}
+		entries = append(entries, entry)
+	}
+	if reader.offset() != int64(len(data)) {
+		t.Errorf("reader stopped at offset %d, want %d", reader.offset(), len(data))
+	}
+	return entries
+}
+
+func sameEntries(t *testing.T, got, want []*pb.KeyValue) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if !bytes.Equal(got[i].Key, want[i].Key) || !bytes.Equal(got[i].Value, want[i].Value) ||
+			got[i].ExpireAt != want[i].ExpireAt {
+			t.Errorf("entry %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestRemapListSet(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    prefixRemap
+		wantErr bool
+	}{
+		{value: "user:=u:", want: prefixRemap{"user:", "u:"}},
+		{value: "user:=", want: prefixRemap{"user:", ""}},
+		{value: "a=b=c", want: prefixRemap{"a", "b=c"}},
+		{value: "hex:00ff=base64:YQ==", want: prefixRemap{"\x00\xff", "a"}},
+		{value: "user:", wantErr: true},
+		{value: "=u:", wantErr: true},
+		{value: "hex:zz=a", wantErr: true},
+		{value: "a=base64:!", wantErr: true},
+	}
+	for _, tt := range tests {
+		var l remapList
+		err := l.Set(tt.value)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Set(%q) = %v, want an error", tt.value, l)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Set(%q) failed: %v", tt.value, err)
+			continue
+		}
+		if len(l) != 1 || l[0] != tt.want {
+			t.Errorf("Set(%q) = %+v, want %+v", tt.value, l, tt.want)
+		}
+	}
+}
+
+func TestRemapListApply(t *testing.T) {
+	l := remapList{{"user:", "u:"}, {"user:admin:", "admin:"}, {"order:", ""}, {"\x00", "bin:"}}
+	tests := []struct {
+		key, want string
+	}{
+		{"user:1", "u:1"},
+		{"user:", "u:"},
+		{"user:admin:1", "u:admin:1"}, // the first matching rule wins
+		{"order:7", "7"},
+		{"\x00\x01", "bin:\x01"},
+		{"user", "user"},
+		{"other:user:1", "other:user:1"},
+	}
+	for _, tt := range tests {
+		if got := l.apply(tt.key); got != tt.want {
+			t.Errorf("apply(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+	if got := remapList(nil).apply("user:1"); got != "user:1" {
+		t.Errorf("apply without rules = %q, want the key unchanged", got)
+	}
+}
+
+func TestEntryReaderRoundTrip(t *testing.T) {
+	entries := testEntries("k", 12)
+	for _, format := range []outputFormat{outputNDJSON, outputCSV} {
+		t.Run(string(format), func(t *testing.T) {
+			// Encode two pages the way export does, remembering where the
+			// second one starts.
+			var buf bytes.Buffer
+			if err := encodeEntries(&buf, format, entries[:5], true); err != nil {
+				t.Fatalf("encodeEntries failed: %v", err)
+			}
+			boundary := int64(buf.Len())
+			if err := encodeEntries(&buf, format, entries[5:], false); err != nil {
+				t.Fatalf("encodeEntries failed: %v", err)
+			}
+			data := buf.Bytes()
+			sameEntries(t, readEntries(t, data, format), entries)
+
+			// A reader started at the page boundary, as import does when it
+			// resumes, reads the rest of the entries and reports offsets of
+			// the whole file.
+			var reader entryReader
+			if format == outputCSV {
+				reader = newCSVEntryReader(bytes.NewReader(data[boundary:]), boundary)
+			} else {
+				reader = &ndjsonEntryReader{r: bufio.NewReader(bytes.NewReader(data[boundary:])), off: boundary}
+			}
+			var rest []*pb.KeyValue
+			for {
+				record, err := reader.next()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("failed to read from offset %d: %v", boundary, err)
+				}
+				entry, err := record.keyValue()
+				if err != nil {
+					t.Fatalf("failed to decode entry: %v", err)
+				}
+				rest = append(rest, entry)
+			}
+			sameEntries(t, rest, entries[5:])
+			if reader.offset() != int64(len(data)) {
+				t.Errorf("reader resumed at %d stopped at offset %d, want %d", boundary, reader.offset(), len(data))
+			}
+		})
+	}
+}
+
+func TestEntryReaderOffsets(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  outputFormat
+		data    string
+		offsets []int64 // offset after each record
+	}{
+		{"ndjson", outputNDJSON, "{\"key\":\"a\",\"value\":\"1\"}\n{\"key\":\"b\",\"value\":\"2\"}\n", []int64{24, 48}},
+		{"ndjson blank lines", outputNDJSON, "\n{\"key\":\"a\",\"value\":\"1\"}\n\n  \n{\"key\":\"b\",\"value\":\"2\"}", []int64{25, 52}},
+		{"csv with header", outputCSV, "key,value,expire_at\na,1,\nb,2,\n", []int64{25, 30}},
+		{"csv with short header", outputCSV, "key,value\na,1\nb,2\n", []int64{14, 18}},
+		{"csv without header", outputCSV, "a,1\nb,2\n", []int64{4, 8}},
+		{"csv quoted newline", outputCSV, "a,\"1\n2\"\nb,2\n", []int64{8, 12}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reader entryReader
+			if tt.format == outputCSV {
+				reader = newCSVEntryReader(strings.NewReader(tt.data), 0)
+			} else {
+				reader = &ndjsonEntryReader{r: bufio.NewReader(strings.NewReader(tt.data))}
+			}
+			var offsets []int64
+			for {
+				_, err := reader.next()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("next failed after %d records: %v", len(offsets), err)
+				}
+				offsets = append(offsets, reader.offset())
+			}
+			if !slices.Equal(offsets, tt.offsets) {
+				t.Errorf("offsets = %v, want %v", offsets, tt.offsets)
+			}
+		})
+	}
+}
+
+func TestCSVEntryReader(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		base    int64
+		want    []entryRecord
+		wantErr bool
+	}{
+		{name: "header", data: "key,value,expire_at\na,1,5\n", want: []entryRecord{{"a", "1", 5}}},
+		{name: "no expiry", data: "a,1,\nb,2\n", want: []entryRecord{{"a", "1", 0}, {"b", "2", 0}}},
+		{name: "header only at the start", data: "key,value\n", base: 10, want: []entryRecord{{"key", "value", 0}}},
+		{name: "one field", data: "a\n", wantErr: true},
+		{name: "four fields", data: "a,1,2,3\n", wantErr: true},
+		{name: "bad expiry", data: "a,1,soon\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := newCSVEntryReader(strings.NewReader(tt.data), tt.base)
+			var got []entryRecord
+			for {
+				record, err := reader.next()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					if !tt.wantErr {
+						t.Fatalf("next failed: %v", err)
+					}
+					return
+				}
+				got = append(got, record)
+			}
+			if tt.wantErr {
+				t.Fatalf("read %v, want an error", got)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("read %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransferCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dump.ndjson.checkpoint")
+
+	cp, err := loadCheckpoint(path)
+	if cp != nil || err != nil {
+		t.Fatalf("loadCheckpoint of a missing file = %v, %v, want nil, nil", cp, err)
+	}
+
+	want := &transferCheckpoint{
+		File:      "dump.ndjson",
+		Format:    outputNDJSON,
+		Offset:    1234,
+		Entries:   56,
+		Prefix:    []byte("\x00user:"),
+		PageToken: "dXNlcjo1Ng",
+	}
+	if err := saveCheckpoint(path, want); err != nil {
+		t.Fatalf("saveCheckpoint failed: %v", err)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("saveCheckpoint left %s.tmp behind", path)
+	}
+	got, err := loadCheckpoint(path)
+	if err != nil {
+		t.Fatalf("loadCheckpoint failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("loadCheckpoint = %+v, want %+v", got, want)
+	}
+
+	if err := removeCheckpoint(path); err != nil {
+		t.Fatalf("removeCheckpoint failed: %v", err)
+	}
+	if err := removeCheckpoint(path); err != nil {
+		t.Errorf("removeCheckpoint of a missing file failed: %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadCheckpoint(path); err == nil {
+		t.Error("loadCheckpoint of an invalid file succeeded")
+	}
+}
+
+// transferFile returns the options of a transfer of a file in a new
+// temporary directory.
+func transferFile(t *testing.T, format outputFormat, batchSize int) transferOptions {
+	t.Helper()
+	o := transferOptions{
+		file:      filepath.Join(t.TempDir(), "dump."+string(format)),
+		batchSize: batchSize,
+	}
+	if err := o.validate(); err != nil {
+		t.Fatalf("validate failed: %v", err)
+	}
+	return o
+}
+
+func TestExportResume(t *testing.T) {
+	ctx := context.Background()
+	entries := testEntries("user:", 25)
+	others := testEntries("order:", 3)
+
+	for _, format := range []outputFormat{outputNDJSON, outputCSV} {
+		t.Run(string(format), func(t *testing.T) {
+			srv := newTransferServer(append(slices.Clone(entries), others...))
+			srv.failScanAt = 3
+			c := srv.dial(t)
+			o := transferFile(t, format, 10)
+
+			if err := runExport(ctx, c, "user:", o); err == nil {
+				t.Fatal("export succeeded despite the failed scan")
+			}
+			cp, err := loadCheckpoint(o.checkpoint)
+			if err != nil || cp == nil {
+				t.Fatalf("no checkpoint after the failed export: %v", err)
+			}
+			if cp.Entries != 20 {
+				t.Errorf("checkpoint after %d entries, want 20", cp.Entries)
+			}
+
+			// Entries written after the checkpoint, such as a page written
+			// just before the export was killed, are dropped by the resumed
+			// export.
+			f, err := os.OpenFile(o.file, os.O_WRONLY|os.O_APPEND, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			encodeEntries(f, format, testEntries("user:zz", 40), false)
+			f.Close()
+
+			o.resume = true
+			if err := runExport(ctx, c, "user:", o); err != nil {
+				t.Fatalf("resumed export failed: %v", err)
+			}
+			if _, err := os.Stat(o.checkpoint); !os.IsNotExist(err) {
+				t.Errorf("checkpoint %s left after the export finished", o.checkpoint)
+			}
+
+			data, err := os.ReadFile(o.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if format == outputCSV && bytes.Count(data, []byte("key,value,expire_at")) != 1 {
+				t.Errorf("export wrote the CSV header %d times, want once", bytes.Count(data, []byte("key,value,expire_at")))
+			}
+			sameEntries(t, readEntries(t, data, format), entries)
+		})
+	}
+}
+
+func TestExportResumeOtherPrefix(t *testing.T) {
+	ctx := context.Background()
+	srv := newTransferServer(testEntries("user:", 25))
+	srv.failScanAt = 2
+	c := srv.dial(t)
+	o := transferFile(t, outputNDJSON, 10)
+
+	if err := runExport(ctx, c, "user:", o); err == nil {
+		t.Fatal("export succeeded despite the failed scan")
+	}
+	o.resume = true
+	if err := runExport(ctx, c, "order:", o); err == nil {
+		t.Error("export of another prefix resumed from the checkpoint")
+	}
+}
+
+func TestImportResume(t *testing.T) {
+	ctx := context.Background()
+	entries := testEntries("user:", 25)
+	expired := &pb.KeyValue{Key: []byte("user:old"), Value: []byte("x"), ExpireAt: time.Now().Add(-time.Hour).Unix()}
+	records := append(slices.Clone(entries[:12]), append([]*pb.KeyValue{expired}, entries[12:]...)...)
+
+	var remaps remapList
+	if err := remaps.Set("user:=u:"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		failBatchAt int
+		checkpoint  int64 // entries read at the last checkpoint
+	}{
+		{"after the first batch", 2, 10},
+		// The last batch holds the 5 entries left after two full batches.
+		{"in the partial last batch", 3, 21},
+	}
+	for _, format := range []outputFormat{outputNDJSON, outputCSV} {
+		for _, tt := range tests {
+			t.Run(string(format)+"/"+tt.name, func(t *testing.T) {
+				o := transferFile(t, format, 10)
+				var buf bytes.Buffer
+				if err := encodeEntries(&buf, format, records, true); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(o.file, buf.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+
+				srv := newTransferServer(nil)
+				srv.failBatchAt = tt.failBatchAt
+				c := srv.dial(t)
+
+				if err := runImport(ctx, c, remaps, o); err == nil {
+					t.Fatal("import succeeded despite the failed batch")
+				}
+				cp, err := loadCheckpoint(o.checkpoint)
+				if err != nil || cp == nil {
+					t.Fatalf("no checkpoint after the failed import: %v", err)
+				}
+				if cp.Entries != tt.checkpoint {
+					t.Errorf("checkpoint after %d entries, want %d", cp.Entries, tt.checkpoint)
+				}
+
+				o.resume = true
+				if err := runImport(ctx, c, remaps, o); err != nil {
+					t.Fatalf("resumed import failed: %v", err)
+				}
+				if _, err := os.Stat(o.checkpoint); !os.IsNotExist(err) {
+					t.Errorf("checkpoint %s left after the import finished", o.checkpoint)
+				}
+
+				// Every live entry is stored once, under its remapped key.
+				if len(srv.puts) != len(entries) {
+					t.Errorf("imported %d keys, want %d", len(srv.puts), len(entries))
+				}
+				for _, entry := range entries {
+					key := remaps.apply(string(entry.Key))
+					if n := srv.puts[key]; n != 1 {
+						t.Errorf("key %q was put %d times, want once", key, n)
+						continue
+					}
+					if got := srv.entries[key]; !bytes.Equal(got.Value, entry.Value) || got.ExpireAt != entry.ExpireAt {
+						t.Errorf("key %q = %v, want %v", key, got, entry)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestTransferRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	entries := testEntries("user:", 23)
+
+	for _, format := range []outputFormat{outputNDJSON, outputCSV} {
+		t.Run(string(format), func(t *testing.T) {
+			o := transferFile(t, format, 7)
+			if err := runExport(ctx, newTransferServer(entries).dial(t), "user:", o); err != nil {
+				t.Fatalf("export failed: %v", err)
+			}
+
+			dst := newTransferServer(nil)
+			o.batchSize = 5
+			if err := runImport(ctx, dst.dial(t), nil, o); err != nil {
+				t.Fatalf("import failed: %v", err)
+			}
+			if len(dst.entries) != len(entries) {
+				t.Fatalf("imported %d entries, want %d", len(dst.entries), len(entries))
+			}
+			for _, entry := range entries {
+				got := dst.entries[string(entry.Key)]
+				if got == nil || !bytes.Equal(got.Value, entry.Value) || got.ExpireAt != entry.ExpireAt {
+					t.Errorf("key %q = %v, want %v", entry.Key, got, entry)
+				}
+			}
+		})
+	}
+}
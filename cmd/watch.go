@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	pb "github.com/TFMV/slatedb_demo/proto/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// watchRetryDelay is how long the live tail waits before reconnecting.
+const watchRetryDelay = time.Second
+
+// handleWatch prints the changes to a prefix as they happen, until Enter is
+// pressed.
+func handleWatch(client *SlateDBClient) {
+	titleColor.Print("\n=== Watch Prefix ===\n\n")
+	prefix := readBinaryInput("Enter prefix to watch (empty for all keys): ")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		tailEvents(ctx, client, prefix)
+	}()
+
+	infoColor.Printf("Watching '%s', press Enter to stop...\n\n", formatBytes([]byte(prefix)))
+	readInput("")
+	cancel()
+	<-done
+}
+
+// tailEvents prints the events for prefix until ctx is canceled. When the
+// stream breaks it reconnects and resumes after the last event printed.
+func tailEvents(ctx context.Context, client *SlateDBClient, prefix string) {
+	var next uint64
+	for {
+		var err error
+		for event, werr := range client.Watch(ctx, prefix, next) {
+			if werr != nil {
+				err = werr
+				break
+			}
+			printWatchEvent(event)
+			next = event.Sequence + 1
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		switch status.Code(err) {
+		case codes.Unavailable, codes.ResourceExhausted:
+			infoColor.Printf("ℹ Watch interrupted (%v), reconnecting...\n", status.Convert(err).Message())
+		case codes.OutOfRange:
+			// The server no longer has the events we missed.
+			errorColor.Println("✗ Some events were missed, continuing with new events")
+			next = 0
+		default:
+			errorColor.Printf("✗ Error: %v\n", err)
+			infoColor.Println("Press Enter to return to the main menu")
+			return
+		}
+
+		select {
+		case <-time.After(watchRetryDelay):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func printWatchEvent(event *pb.WatchEvent) {
+	fmt.Printf("%s #%d ", time.Now().Format(time.TimeOnly), event.Sequence)
+	switch event.Type {
+	case pb.WatchEvent_PUT:
+		successColor.Print("PUT    ")
+		keyColor.Print(formatBytes(event.Key))
+		fmt.Print(" = ")
+		valueColor.Print(formatBytes(event.Value))
+		if event.ExpireAt != 0 {
+			fmt.Printf(" (expires %s)", formatExpiry(event.ExpireAt))
+		}
+		fmt.Println()
+	case pb.WatchEvent_DELETE:
+		errorColor.Print("DELETE ")
+		keyColor.Println(formatBytes(event.Key))
+	}
+}
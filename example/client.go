@@ -2,59 +2,65 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"time"
 
-	pb "github.com/TFMV/slatedb_demo/proto"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"github.com/TFMV/slatedb_demo/client"
 )
 
 func main() {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	// Connect to the gRPC server
-	conn, err := grpc.NewClient("localhost:8080", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	// Connect to the server. The demo server runs without TLS unless
+	// TLS_CERT_FILE is set; use client.WithTLSFiles to connect to it then.
+	c, err := client.New(
+		client.WithAddress(client.DefaultAddress),
+		client.WithInsecure(),
+		client.WithTimeout(2*time.Second),
+	)
 	if err != nil {
 		log.Fatalf("Failed to connect to server: %v", err)
 	}
-	defer conn.Close()
-
-	client := pb.NewSlateDBClient(conn)
+	defer c.Close()
 
 	// Example 1: Put a key-value pair
-	putResp, err := client.Put(ctx, &pb.PutRequest{
-		Key:   "exampleKey",
-		Value: "exampleValue",
-	})
-	if err != nil {
+	if err := c.Put(ctx, []byte("example:key"), []byte("exampleValue"), 0); err != nil {
 		log.Fatalf("Put failed: %v", err)
 	}
-	log.Printf("Put response: %s", putResp.Message)
+	log.Printf("Put example:key")
 
 	// Example 2: Get the value for a key
-	getResp, err := client.Get(ctx, &pb.GetRequest{
-		Key: "exampleKey",
-	})
+	value, err := c.Get(ctx, []byte("example:key"))
 	if err != nil {
 		log.Fatalf("Get failed: %v", err)
 	}
-	log.Printf("Get response: Key = %s, Value = %s", "exampleKey", getResp.Value)
+	log.Printf("Get response: Key = %s, Value = %s", "example:key", value)
 
-	// Example 3: Delete a key
-	delResp, err := client.Delete(ctx, &pb.DeleteRequest{
-		Key: "exampleKey",
-	})
-	if err != nil {
+	// Example 3: Scan the keys with a prefix
+	for entry, err := range c.PrefixScanStream(ctx, []byte("example:"), 0) {
+		if err != nil {
+			log.Fatalf("Scan failed: %v", err)
+		}
+		log.Printf("Scan: %s = %s", entry.Key, entry.Value)
+	}
+
+	// Example 4: Delete a key
+	if err := c.Delete(ctx, []byte("example:key")); err != nil {
 		log.Fatalf("Delete failed: %v", err)
 	}
-	log.Printf("Delete response: %s", delResp.Message)
+	log.Printf("Deleted example:key")
 
 	// Attempt to get the deleted key
-	getResp, err = client.Get(ctx, &pb.GetRequest{
-		Key: "exampleKey",
-	})
-	fmt.Println(getResp)
+	_, err = c.Get(ctx, []byte("example:key"))
+	switch {
+	case errors.Is(err, client.ErrNotFound):
+		fmt.Println("Found after delete: false")
+	case err != nil:
+		log.Fatalf("Get failed: %v", err)
+	default:
+		fmt.Println("Found after delete: true")
+	}
 }
@@ -0,0 +1,223 @@
+// Package tlsconfig builds the TLS configurations of the server and the
+// clients from PEM files. The files are checked periodically and reloaded
+// when they change, so that certificates can be rotated without a restart.
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Files are the PEM files of one end of a connection.
+type Files struct {
+	// CAFile holds the certificates that verify the peer. A server that has
+	// one requires client certificates; a client without one verifies the
+	// server with the system roots.
+	CAFile string
+
+	// CertFile and KeyFile hold the certificate chain presented to the peer
+	// and its private key. They are required for a server and optional for
+	// a client.
+	CertFile string
+	KeyFile  string
+}
+
+func (f Files) validate() error {
+	if (f.CertFile == "") != (f.KeyFile == "") {
+		return errors.New("a certificate and a key must be given together")
+	}
+	return nil
+}
+
+// fileStamp identifies a version of a file.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// Reloader holds the certificate and CA pool loaded from Files, and reloads
+// them when one of the files changes. A reload that fails keeps the
+// previous certificates and is tried again at the next check.
+type Reloader struct {
+	files    Files
+	onReload func(error)
+
+	mu     sync.RWMutex
+	cert   *tls.Certificate
+	pool   *x509.CertPool
+	stamps map[string]fileStamp
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewReloader loads files and checks them for changes every interval.
+// onReload, if not nil, is called after every reload with its error.
+func NewReloader(files Files, interval time.Duration, onReload func(error)) (*Reloader, error) {
+	if err := files.validate(); err != nil {
+		return nil, err
+	}
+
+	r := &Reloader{
+		files:    files,
+		onReload: onReload,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+
+	go r.watch(interval)
+	return r, nil
+}
+
+// Close stops checking the files.
+func (r *Reloader) Close() {
+	r.stopOnce.Do(func() { close(r.stop) })
+	<-r.done
+}
+
+func (r *Reloader) watch(interval time.Duration) {
+	defer close(r.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if !r.changed() {
+				continue
+			}
+			err := r.load()
+			if r.onReload != nil {
+				r.onReload(err)
+			}
+		case <-r.stop:
+			return
+		}
+	}
+}
+
+// paths returns the files in use.
+func (r *Reloader) paths() []string {
+	var paths []string
+	for _, path := range []string{r.files.CAFile, r.files.CertFile, r.files.KeyFile} {
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// changed reports whether a file differs from the version last loaded.
+func (r *Reloader) changed() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, path := range r.paths() {
+		info, err := os.Stat(path)
+		if err != nil {
+			// Reported by the reload, and kept until the file is back.
+			return true
+		}
+		if (fileStamp{info.ModTime(), info.Size()}) != r.stamps[path] {
+			return true
+		}
+	}
+	return false
+}
+
+// load reads the files and replaces the certificates if they are all valid.
+func (r *Reloader) load() error {
+	stamps := make(map[string]fileStamp)
+	for _, path := range r.paths() {
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		stamps[path] = fileStamp{info.ModTime(), info.Size()}
+	}
+
+	var cert *tls.Certificate
+	if r.files.CertFile != "" {
+		pair, err := tls.LoadX509KeyPair(r.files.CertFile, r.files.KeyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load certificate: %v", err)
+		}
+		cert = &pair
+	}
+
+	var pool *x509.CertPool
+	if r.files.CAFile != "" {
+		data, err := os.ReadFile(r.files.CAFile)
+		if err != nil {
+			return fmt.Errorf("failed to load CA: %v", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return fmt.Errorf("failed to load CA: no certificates found in %s", r.files.CAFile)
+		}
+	}
+
+	r.mu.Lock()
+	r.cert, r.pool, r.stamps = cert, pool, stamps
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *Reloader) current() (*tls.Certificate, *x509.CertPool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, r.pool
+}
+
+// ServerConfig returns the TLS configuration of a server. Every handshake
+// uses the certificates loaded last. If the files include a CA, clients must
+// present a certificate signed by it.
+func (r *Reloader) ServerConfig() (*tls.Config, error) {
+	if r.files.CertFile == "" {
+		return nil, errors.New("a server requires a certificate and a key")
+	}
+
+	base := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		NextProtos: []string{"h2"},
+	}
+	base.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		cert, pool := r.current()
+		cfg := base.Clone()
+		cfg.GetConfigForClient = nil
+		cfg.Certificates = []tls.Certificate{*cert}
+		if pool != nil {
+			cfg.ClientCAs = pool
+			cfg.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+		return cfg, nil
+	}
+	return base, nil
+}
+
+// ClientConfig returns the TLS configuration of a client. The client
+// certificate, if any, is the one loaded last; the CA pool is the one loaded
+// when the configuration is made, as connections keep a copy of it.
+func (r *Reloader) ClientConfig() *tls.Config {
+	_, pool := r.current()
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		RootCAs:    pool,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			cert, _ := r.current()
+			if cert == nil {
+				return &tls.Certificate{}, nil
+			}
+			return cert, nil
+		},
+	}
+}
@@ -19,15 +19,17 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	SlateDB_Put_FullMethodName         = "/slatedb.SlateDB/Put"
-	SlateDB_Get_FullMethodName         = "/slatedb.SlateDB/Get"
-	SlateDB_Delete_FullMethodName      = "/slatedb.SlateDB/Delete"
-	SlateDB_BatchPut_FullMethodName    = "/slatedb.SlateDB/BatchPut"
-	SlateDB_BatchGet_FullMethodName    = "/slatedb.SlateDB/BatchGet"
-	SlateDB_BatchDelete_FullMethodName = "/slatedb.SlateDB/BatchDelete"
-	SlateDB_PrefixScan_FullMethodName  = "/slatedb.SlateDB/PrefixScan"
-	SlateDB_RangeScan_FullMethodName   = "/slatedb.SlateDB/RangeScan"
-	SlateDB_GetStats_FullMethodName    = "/slatedb.SlateDB/GetStats"
+	SlateDB_Put_FullMethodName              = "/slatedb.SlateDB/Put"
+	SlateDB_Get_FullMethodName              = "/slatedb.SlateDB/Get"
+	SlateDB_Delete_FullMethodName           = "/slatedb.SlateDB/Delete"
+	SlateDB_BatchPut_FullMethodName         = "/slatedb.SlateDB/BatchPut"
+	SlateDB_BatchGet_FullMethodName         = "/slatedb.SlateDB/BatchGet"
+	SlateDB_BatchDelete_FullMethodName      = "/slatedb.SlateDB/BatchDelete"
+	SlateDB_PrefixScan_FullMethodName       = "/slatedb.SlateDB/PrefixScan"
+	SlateDB_RangeScan_FullMethodName        = "/slatedb.SlateDB/RangeScan"
+	SlateDB_PrefixScanStream_FullMethodName = "/slatedb.SlateDB/PrefixScanStream"
+	SlateDB_RangeScanStream_FullMethodName  = "/slatedb.SlateDB/RangeScanStream"
+	SlateDB_GetStats_FullMethodName         = "/slatedb.SlateDB/GetStats"
 )
 
 // SlateDBClient is the client API for SlateDB service.
@@ -47,6 +49,10 @@ type SlateDBClient interface {
 	// Scanning operations
 	PrefixScan(ctx context.Context, in *PrefixScanRequest, opts ...grpc.CallOption) (*PrefixScanResponse, error)
 	RangeScan(ctx context.Context, in *RangeScanRequest, opts ...grpc.CallOption) (*RangeScanResponse, error)
+	// Streaming scans send entries in chunks and are not bound by the
+	// maximum message size
+	PrefixScanStream(ctx context.Context, in *PrefixScanRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ScanChunk], error)
+	RangeScanStream(ctx context.Context, in *RangeScanRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ScanChunk], error)
 	// Statistics and monitoring
 	GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*GetStatsResponse, error)
 }
@@ -139,6 +145,44 @@ func (c *slateDBClient) RangeScan(ctx context.Context, in *RangeScanRequest, opt
 	return out, nil
 }
 
+func (c *slateDBClient) PrefixScanStream(ctx context.Context, in *PrefixScanRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ScanChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SlateDB_ServiceDesc.Streams[0], SlateDB_PrefixScanStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[PrefixScanRequest, ScanChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SlateDB_PrefixScanStreamClient = grpc.ServerStreamingClient[ScanChunk]
+
+func (c *slateDBClient) RangeScanStream(ctx context.Context, in *RangeScanRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ScanChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SlateDB_ServiceDesc.Streams[1], SlateDB_RangeScanStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[RangeScanRequest, ScanChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SlateDB_RangeScanStreamClient = grpc.ServerStreamingClient[ScanChunk]
+
 func (c *slateDBClient) GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*GetStatsResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(GetStatsResponse)
@@ -166,6 +210,10 @@ type SlateDBServer interface {
 	// Scanning operations
 	PrefixScan(context.Context, *PrefixScanRequest) (*PrefixScanResponse, error)
 	RangeScan(context.Context, *RangeScanRequest) (*RangeScanResponse, error)
+	// Streaming scans send entries in chunks and are not bound by the
+	// maximum message size
+	PrefixScanStream(*PrefixScanRequest, grpc.ServerStreamingServer[ScanChunk]) error
+	RangeScanStream(*RangeScanRequest, grpc.ServerStreamingServer[ScanChunk]) error
 	// Statistics and monitoring
 	GetStats(context.Context, *GetStatsRequest) (*GetStatsResponse, error)
 	mustEmbedUnimplementedSlateDBServer()
@@ -202,6 +250,12 @@ func (UnimplementedSlateDBServer) PrefixScan(context.Context, *PrefixScanRequest
 func (UnimplementedSlateDBServer) RangeScan(context.Context, *RangeScanRequest) (*RangeScanResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method RangeScan not implemented")
 }
+func (UnimplementedSlateDBServer) PrefixScanStream(*PrefixScanRequest, grpc.ServerStreamingServer[ScanChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method PrefixScanStream not implemented")
+}
+func (UnimplementedSlateDBServer) RangeScanStream(*RangeScanRequest, grpc.ServerStreamingServer[ScanChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method RangeScanStream not implemented")
+}
 func (UnimplementedSlateDBServer) GetStats(context.Context, *GetStatsRequest) (*GetStatsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetStats not implemented")
 }
@@ -370,6 +424,28 @@ func _SlateDB_RangeScan_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SlateDB_PrefixScanStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(PrefixScanRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SlateDBServer).PrefixScanStream(m, &grpc.GenericServerStream[PrefixScanRequest, ScanChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SlateDB_PrefixScanStreamServer = grpc.ServerStreamingServer[ScanChunk]
+
+func _SlateDB_RangeScanStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(RangeScanRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SlateDBServer).RangeScanStream(m, &grpc.GenericServerStream[RangeScanRequest, ScanChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SlateDB_RangeScanStreamServer = grpc.ServerStreamingServer[ScanChunk]
+
 func _SlateDB_GetStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetStatsRequest)
 	if err := dec(in); err != nil {
@@ -432,6 +508,17 @@ var SlateDB_ServiceDesc = grpc.ServiceDesc{
 			Handler:    _SlateDB_GetStats_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "PrefixScanStream",
+			Handler:       _SlateDB_PrefixScanStream_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "RangeScanStream",
+			Handler:       _SlateDB_RangeScanStream_Handler,
+			ServerStreams: true,
+		},
+	},
 	Metadata: "slatedb.proto",
 }